@@ -2,7 +2,6 @@ module playground
 
 go 1.22
 
-require (
-	github.com/samber/lo v1.47.0 // indirect
-	golang.org/x/text v0.16.0 // indirect
-)
+require github.com/samber/lo v1.47.0
+
+require golang.org/x/text v0.16.0 // indirect
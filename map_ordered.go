@@ -0,0 +1,185 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+)
+
+// OrderedMap holds key-value pairs in a red-black tree sorted by key, like std::map in c++.
+type OrderedMap[K comparable, V any] struct {
+	tree *RBTree[K, V]
+}
+
+func NewOrderedMap[K cmp.Ordered, V any]() *OrderedMap[K, V] {
+	return NewOrderedMapWith[K, V](cmp.Compare[K])
+}
+
+// NewOrderedMapWith instantiates a new empty map with the custom comparator for keys.
+func NewOrderedMapWith[K comparable, V any](comparator RBComparator[K]) *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{tree: NewRBTWithComp[K, V](comparator)}
+}
+
+// Set inserts or replaces the value of the key, O(log n).
+func (m *OrderedMap[K, V]) Set(key K, value V) {
+	m.tree.Put(key, value)
+}
+
+// Get returns the value of the key, second return parameter is false if the key is not in the map.
+func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
+	return m.tree.Get(key)
+}
+
+// GetOrDefault returns the value of the key or def if the key is not in the map.
+func (m *OrderedMap[K, V]) GetOrDefault(key K, def V) V {
+	if value, found := m.tree.Get(key); found {
+		return value
+	}
+	return def
+}
+
+// Has returns true if the key is in the map.
+func (m *OrderedMap[K, V]) Has(key K) bool {
+	return m.tree.lookup(key) != nil
+}
+
+// Ref returns a pointer to the value of the key, inserting the zero value if the key is absent,
+// like m[key] in c++: *m.Ref(key) += x. The pointer is valid until the key is deleted.
+func (m *OrderedMap[K, V]) Ref(key K) *V {
+	node := m.tree.lookup(key)
+	if node == nil {
+		var zero V
+		m.tree.Put(key, zero)
+		node = m.tree.lookup(key)
+	}
+	return &node.Value
+}
+
+// Update replaces the value of the key with f(old value), old value is zero if the key is absent.
+func (m *OrderedMap[K, V]) Update(key K, f func(V) V) {
+	value := m.Ref(key)
+	*value = f(*value)
+}
+
+// Delete removes the key from the map, does nothing if the key is absent.
+func (m *OrderedMap[K, V]) Delete(key K) {
+	m.tree.Remove(key)
+}
+
+// Len returns number of keys in the map.
+func (m *OrderedMap[K, V]) Len() int {
+	return m.tree.Size()
+}
+
+// Empty returns true if map does not contain any keys.
+func (m *OrderedMap[K, V]) Empty() bool {
+	return m.tree.Empty()
+}
+
+// Clear removes all keys from the map.
+func (m *OrderedMap[K, V]) Clear() {
+	m.tree.Clear()
+}
+
+// Keys returns all keys in ascending order.
+func (m *OrderedMap[K, V]) Keys() []K {
+	return m.tree.Keys()
+}
+
+// Values returns all values in ascending order of their keys.
+func (m *OrderedMap[K, V]) Values() []V {
+	return m.tree.Values()
+}
+
+// Floor returns the largest key smaller than or equal to the given key and its value.
+// Third return parameter is false if there is no such key.
+func (m *OrderedMap[K, V]) Floor(key K) (K, V, bool) {
+	return entryOf(m.tree.Floor(key))
+}
+
+// Ceiling returns the smallest key greater than or equal to the given key and its value, like lower_bound in c++.
+// Third return parameter is false if there is no such key.
+func (m *OrderedMap[K, V]) Ceiling(key K) (K, V, bool) {
+	return entryOf(m.tree.Ceiling(key))
+}
+
+// Lower returns the largest key strictly smaller than the given key and its value.
+func (m *OrderedMap[K, V]) Lower(key K) (K, V, bool) {
+	return entryOf(m.tree.Lower(key))
+}
+
+// Higher returns the smallest key strictly greater than the given key and its value, like upper_bound in c++.
+func (m *OrderedMap[K, V]) Higher(key K) (K, V, bool) {
+	return entryOf(m.tree.Higher(key))
+}
+
+// Min returns the smallest key and its value, third return parameter is false if the map is empty.
+func (m *OrderedMap[K, V]) Min() (K, V, bool) {
+	node := m.tree.Left()
+	return entryOf(node, node != nil)
+}
+
+// Max returns the largest key and its value, third return parameter is false if the map is empty.
+func (m *OrderedMap[K, V]) Max() (K, V, bool) {
+	node := m.tree.Right()
+	return entryOf(node, node != nil)
+}
+
+func entryOf[K comparable, V any](node *RBNode[K, V], found bool) (key K, value V, ok bool) {
+	if !found {
+		return key, value, false
+	}
+	return node.Key, node.Value, true
+}
+
+// All returns a sequence of all key-value pairs in ascending order of keys.
+// Can be used with range-over-func (go 1.23+): for k, v := range m.All() { ... }
+// The map must not be modified during the iteration, except for changing values through Ref.
+func (m *OrderedMap[K, V]) All() func(yield func(K, V) bool) {
+	return func(yield func(K, V) bool) {
+		for it := m.tree.Iterator(); it.Next(); {
+			if !yield(it.Key(), it.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// Ascend returns a sequence of key-value pairs with keys in [from, to) in ascending order, see All.
+func (m *OrderedMap[K, V]) Ascend(from, to K) func(yield func(K, V) bool) {
+	return func(yield func(K, V) bool) {
+		node, found := m.tree.Ceiling(from)
+		if !found {
+			return
+		}
+		for it := m.tree.IteratorAt(node); m.tree.Comparator(it.Key(), to) < 0; {
+			if !yield(it.Key(), it.Value()) || !it.Next() {
+				return
+			}
+		}
+	}
+}
+
+// Descend returns a sequence of key-value pairs with keys in (to, from] in descending order, see All.
+func (m *OrderedMap[K, V]) Descend(from, to K) func(yield func(K, V) bool) {
+	return func(yield func(K, V) bool) {
+		node, found := m.tree.Floor(from)
+		if !found {
+			return
+		}
+		for it := m.tree.IteratorAt(node); m.tree.Comparator(it.Key(), to) > 0; {
+			if !yield(it.Key(), it.Value()) || !it.Prev() {
+				return
+			}
+		}
+	}
+}
+
+// String returns a string representation of container
+func (m *OrderedMap[K, V]) String() string {
+	items := []string{}
+	for it := m.tree.Iterator(); it.Next(); {
+		items = append(items, fmt.Sprintf("%v:%v", it.Key(), it.Value()))
+	}
+	return "TreeMap\n" + strings.Join(items, ", ")
+}
@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+// Ref must keep pointing to the stored value when other keys are deleted:
+// Remove used to copy the predecessor into the deleted node and detach the predecessor's node.
+func TestOrderedMapRefSurvivesDelete(t *testing.T) {
+	m := NewOrderedMap[int, int]()
+	for k := 0; k < 64; k++ {
+		m.Set(k, k*10)
+	}
+	refs := make(map[int]*int)
+	for k := 0; k < 64; k++ {
+		refs[k] = m.Ref(k)
+	}
+
+	for k := 1; k < 64; k += 2 {
+		m.Delete(k)
+	}
+	for k := 0; k < 64; k += 2 {
+		*refs[k] += 1000
+	}
+
+	for k := 0; k < 64; k += 2 {
+		if got, _ := m.Get(k); got != k*10+1000 {
+			t.Fatalf("Get(%d) = %d after update through Ref, want %d", k, got, k*10+1000)
+		}
+	}
+}
+
+func TestOrderedMapUpdate(t *testing.T) {
+	m := NewOrderedMap[string, int]()
+	for _, w := range []string{"b", "a", "c", "a", "b", "a"} {
+		*m.Ref(w)++
+	}
+	m.Update("d", func(v int) int { return v + 10 })
+
+	want := map[string]int{"a": 3, "b": 2, "c": 1, "d": 10}
+	if m.Len() != len(want) {
+		t.Fatalf("Len() = %d, want %d", m.Len(), len(want))
+	}
+	for k, v := range want {
+		if got := m.GetOrDefault(k, -1); got != v {
+			t.Errorf("GetOrDefault(%q) = %d, want %d", k, got, v)
+		}
+	}
+	if got := m.GetOrDefault("z", -1); got != -1 {
+		t.Errorf("GetOrDefault(missing) = %d, want -1", got)
+	}
+}
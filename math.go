@@ -17,73 +17,133 @@ func calcInverses(n int64) {
 	}
 }
 
-type Mint int64
+// Modulus is a compile-time modulus for ModInt, the value must be less than 2^31 so that products fit in int64.
+type Modulus interface {
+	Mod() int64
+}
 
-func NewMint(val int64) Mint {
-	val = val % MOD
+type Mod998244353 struct{}
+
+func (Mod998244353) Mod() int64 { return 998244353 }
+
+type Mod1e9p7 struct{}
+
+func (Mod1e9p7) Mod() int64 { return 1_000_000_007 }
+
+// DynamicMod takes the modulus from the global MOD, see setMOD.
+type DynamicMod struct{}
+
+func (DynamicMod) Mod() int64 { return MOD }
+
+// ModInt is an integer modulo M.Mod(). NewModInt and all operators return values in [0, mod),
+// raw conversions like ModInt[M](-1) or untyped constants (x.add(-1)) are reduced by every operator.
+type ModInt[M Modulus] int64
+
+type (
+	Mint998 = ModInt[Mod998244353]
+	Mint1e9 = ModInt[Mod1e9p7]
+	Mint    = ModInt[DynamicMod]
+)
+
+// NewModInt reduces any int64 (including negative) modulo M.Mod(): NewModInt[Mod998244353](-1) == 998244352.
+func NewModInt[M Modulus](val int64) ModInt[M] {
+	var m M
+	val %= m.Mod()
 	if val < 0 {
-		val += MOD
+		val += m.Mod()
 	}
-	return Mint(val)
+	return ModInt[M](val)
+}
+
+// NewMint creates a number modulo the global MOD.
+func NewMint(val int64) Mint {
+	return NewModInt[DynamicMod](val)
 }
 
-func (m Mint) mul(a any) Mint {
-	aInt64 := getInt64(a) % MOD
-	return Mint((int64(m) * aInt64) % MOD)
+func (x ModInt[M]) mod() int64 {
+	var m M
+	return m.Mod()
 }
 
-func (m Mint) div(a any) Mint {
-	aInt64 := getInt64(a)
-	if aInt64 <= 0 || aInt64 >= int64(len(inv)) {
-		panic("invalid argument a")
+// norm reduces a value that did not come from NewModInt or an operator
+func (x ModInt[M]) norm() ModInt[M] {
+	if v := int64(x); v < 0 || v >= x.mod() {
+		return NewModInt[M](v)
 	}
-	return m.mul(Mint(inv[aInt64]))
+	return x
 }
 
-func (m Mint) add(a any) Mint {
-	aInt64 := getInt64(a) % MOD
-	res := int64(m) + aInt64
+func (x ModInt[M]) add(a ModInt[M]) ModInt[M] {
+	x, a = x.norm(), a.norm()
+	res := int64(x) + int64(a)
+	if res >= x.mod() {
+		res -= x.mod()
+	}
+	return ModInt[M](res)
+}
+
+func (x ModInt[M]) sub(a ModInt[M]) ModInt[M] {
+	x, a = x.norm(), a.norm()
+	res := int64(x) - int64(a)
 	if res < 0 {
-		res += MOD
+		res += x.mod()
 	}
-	return Mint(res % MOD)
+	return ModInt[M](res)
+}
+
+func (x ModInt[M]) neg() ModInt[M] {
+	x = x.norm()
+	if x == 0 {
+		return 0
+	}
+	return ModInt[M](x.mod() - int64(x))
+}
+
+func (x ModInt[M]) mul(a ModInt[M]) ModInt[M] {
+	x, a = x.norm(), a.norm()
+	return ModInt[M](int64(x) * int64(a) % x.mod())
+}
+
+// div panics if a is not invertible, see inv.
+func (x ModInt[M]) div(a ModInt[M]) ModInt[M] {
+	return x.mul(a.inv())
 }
 
-func (m Mint) pow(p any) Mint {
-	pn := getInt64(p)
-	a := m
-	res := NewMint(1)
-	for pn > 0 {
-		if pn&1 != 0 {
+// pow returns x^p in O(log p), p must be non-negative.
+func (x ModInt[M]) pow(p int64) ModInt[M] {
+	if p < 0 {
+		panic("ModInt: negative power, use inv().pow(-p)")
+	}
+	res := NewModInt[M](1)
+	for a := x.norm(); p > 0; p >>= 1 {
+		if p&1 != 0 {
 			res = res.mul(a)
 		}
 		a = a.mul(a)
-		pn >>= 1
 	}
-	return m
+	return res
 }
 
-func (m Mint) int64() int64 {
-	return int64(m)
+// inv returns the modular inverse via the extended Euclidean algorithm,
+// works for any modulus as long as gcd(x, mod) == 1, panics otherwise.
+func (x ModInt[M]) inv() ModInt[M] {
+	a, b := int64(x.norm()), x.mod()
+	u, v := int64(1), int64(0)
+	for b != 0 {
+		t := a / b
+		a, b = b, a-t*b
+		u, v = v, u-t*v
+	}
+	if a != 1 {
+		panic("ModInt: value is not invertible")
+	}
+	return NewModInt[M](u)
 }
 
-func (m Mint) int() int {
-	return int(m)
+func (x ModInt[M]) int64() int64 {
+	return int64(x.norm())
 }
 
-func getInt64(a any) int64 {
-	var val int64
-	switch v := a.(type) {
-	case Mint:
-		val = int64(v)
-	case int64:
-		val = v
-	case int:
-		val = int64(v)
-	case int32:
-		val = int64(v)
-	default:
-		panic("unsupported type")
-	}
-	return val
+func (x ModInt[M]) int() int {
+	return int(x.norm())
 }
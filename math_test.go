@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestModIntReducesRawOperands(t *testing.T) {
+	const mod = 998244353
+	var x Mint998
+	if got := x.add(-1).int64(); got != mod-1 {
+		t.Errorf("0.add(-1) = %d, want %d", got, mod-1)
+	}
+	if got := x.sub(-1).int64(); got != 1 {
+		t.Errorf("0.sub(-1) = %d, want 1", got)
+	}
+	if got := Mint998(-3).neg().int64(); got != 3 {
+		t.Errorf("Mint998(-3).neg() = %d, want 3", got)
+	}
+	if got, want := NewModInt[Mod998244353](2).mul(1e12).int64(), int64(2*(1_000_000_000_000%mod)%mod); got != want {
+		t.Errorf("2.mul(1e12) = %d, want %d", got, want)
+	}
+	if got := Mint998(-1).pow(3).int64(); got != mod-1 {
+		t.Errorf("(-1)^3 = %d, want %d", got, mod-1)
+	}
+}
+
+func TestModIntPowInv(t *testing.T) {
+	two := NewModInt[Mod1e9p7](2)
+	if got := two.pow(1_000_000_006).int64(); got != 1 {
+		t.Errorf("2^(p-1) = %d, want 1", got)
+	}
+	for v := int64(1); v < 1000; v++ {
+		x := NewModInt[Mod1e9p7](v)
+		if got := x.mul(x.inv()).int64(); got != 1 {
+			t.Fatalf("%d * inv(%d) = %d, want 1", v, v, got)
+		}
+	}
+
+	setMOD(10)
+	if got := NewMint(3).inv().int64(); got != 7 {
+		t.Errorf("inv(3) mod 10 = %d, want 7", got)
+	}
+}
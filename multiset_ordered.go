@@ -0,0 +1,221 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// OrderedMultiset holds elements with repetitions in a red-black tree.
+// Every copy is a separate node keyed by (value, id), so order statistics of the tree count multiplicities.
+type OrderedMultiset[T comparable] struct {
+	tree       *RBTree[multisetKey[T], struct{}]
+	comparator RBComparator[T]
+	nextID     int
+}
+
+type multisetKey[T comparable] struct {
+	value T
+	id    int
+}
+
+func NewOrderedMultiset[T cmp.Ordered](values ...T) *OrderedMultiset[T] {
+	return NewOrderedMultisetWith[T](cmp.Compare[T], values...)
+}
+
+// NewOrderedMultisetWith instantiates a new empty multiset with the custom comparator.
+func NewOrderedMultisetWith[T comparable](comparator RBComparator[T], values ...T) *OrderedMultiset[T] {
+	ms := &OrderedMultiset[T]{comparator: comparator}
+	ms.tree = NewRBTWithComp[multisetKey[T], struct{}](func(x, y multisetKey[T]) int {
+		if c := comparator(x.value, y.value); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.id, y.id)
+	})
+	ms.Add(values...)
+	return ms
+}
+
+// first and last are keys before all and after all copies of the value
+func (ms *OrderedMultiset[T]) first(value T) multisetKey[T] {
+	return multisetKey[T]{value: value, id: math.MinInt}
+}
+
+func (ms *OrderedMultiset[T]) last(value T) multisetKey[T] {
+	return multisetKey[T]{value: value, id: math.MaxInt}
+}
+
+// Add adds the items (one or more) to the multiset, O(log n) each.
+func (ms *OrderedMultiset[T]) Add(items ...T) {
+	for _, item := range items {
+		ms.tree.Put(multisetKey[T]{value: item, id: ms.nextID}, itemExists)
+		ms.nextID++
+	}
+}
+
+// Remove removes one copy of the item, returns false if the item is not in the multiset.
+func (ms *OrderedMultiset[T]) Remove(item T) bool {
+	node, found := ms.tree.Ceiling(ms.first(item))
+	if !found || ms.comparator(node.Key.value, item) != 0 {
+		return false
+	}
+	ms.tree.Remove(node.Key)
+	return true
+}
+
+// RemoveAll removes all copies of the item and returns how many were removed, O(count * log n).
+func (ms *OrderedMultiset[T]) RemoveAll(item T) int {
+	removed := 0
+	for ms.Remove(item) {
+		removed++
+	}
+	return removed
+}
+
+// Count returns the number of copies of the item in O(log n).
+func (ms *OrderedMultiset[T]) Count(item T) int {
+	return ms.tree.Rank(ms.last(item)) - ms.tree.Rank(ms.first(item))
+}
+
+// Contains checks whether the item is present in the multiset.
+func (ms *OrderedMultiset[T]) Contains(item T) bool {
+	return ms.Count(item) > 0
+}
+
+// Size returns number of elements including repetitions.
+func (ms *OrderedMultiset[T]) Size() int {
+	return ms.tree.Size()
+}
+
+// Empty returns true if multiset does not contain any elements.
+func (ms *OrderedMultiset[T]) Empty() bool {
+	return ms.tree.Empty()
+}
+
+// Clear clears all values in the multiset.
+func (ms *OrderedMultiset[T]) Clear() {
+	ms.tree.Clear()
+}
+
+// Values returns all elements in sorted order, repeated as many times as they were added.
+func (ms *OrderedMultiset[T]) Values() []T {
+	values := make([]T, 0, ms.Size())
+	for it := ms.tree.Iterator(); it.Next(); {
+		values = append(values, it.Key().value)
+	}
+	return values
+}
+
+// At returns the i-th smallest element (0-based) counting repetitions, O(log n). Panics if i is out of range.
+func (ms *OrderedMultiset[T]) At(i int) T {
+	key, found := ms.tree.KthKey(i)
+	if !found {
+		panic(fmt.Sprintf("OrderedMultiset: index %d out of range [0, %d)", i, ms.Size()))
+	}
+	return key.value
+}
+
+// CountLess returns the number of elements strictly less than the item counting repetitions, O(log n).
+func (ms *OrderedMultiset[T]) CountLess(item T) int {
+	return ms.tree.Rank(ms.first(item))
+}
+
+// LowerBound returns the smallest element greater than or equal to the item, like lower_bound in c++.
+// Second return parameter is false if there is no such element.
+func (ms *OrderedMultiset[T]) LowerBound(item T) (T, bool) {
+	return multisetValue(ms.tree.Ceiling(ms.first(item)))
+}
+
+// UpperBound returns the smallest element strictly greater than the item, like upper_bound in c++.
+// Second return parameter is false if there is no such element.
+func (ms *OrderedMultiset[T]) UpperBound(item T) (T, bool) {
+	return multisetValue(ms.tree.Higher(ms.last(item)))
+}
+
+// Floor returns the largest element smaller than or equal to the item.
+func (ms *OrderedMultiset[T]) Floor(item T) (T, bool) {
+	return multisetValue(ms.tree.Floor(ms.last(item)))
+}
+
+// Lower returns the largest element strictly smaller than the item.
+func (ms *OrderedMultiset[T]) Lower(item T) (T, bool) {
+	return multisetValue(ms.tree.Lower(ms.first(item)))
+}
+
+// Min returns the smallest element, second return parameter is false if the multiset is empty.
+func (ms *OrderedMultiset[T]) Min() (T, bool) {
+	node := ms.tree.Left()
+	return multisetValue(node, node != nil)
+}
+
+// Max returns the largest element, second return parameter is false if the multiset is empty.
+func (ms *OrderedMultiset[T]) Max() (T, bool) {
+	node := ms.tree.Right()
+	return multisetValue(node, node != nil)
+}
+
+// PopMin removes one copy of the smallest element and returns it.
+func (ms *OrderedMultiset[T]) PopMin() (T, bool) {
+	node := ms.tree.Left()
+	if node != nil {
+		ms.tree.Remove(node.Key)
+	}
+	return multisetValue(node, node != nil)
+}
+
+// PopMax removes one copy of the largest element and returns it.
+func (ms *OrderedMultiset[T]) PopMax() (T, bool) {
+	node := ms.tree.Right()
+	if node != nil {
+		ms.tree.Remove(node.Key)
+	}
+	return multisetValue(node, node != nil)
+}
+
+func multisetValue[T comparable](node *RBNode[multisetKey[T], struct{}], found bool) (value T, ok bool) {
+	if !found {
+		return value, false
+	}
+	return node.Key.value, true
+}
+
+// Ascend returns a sequence of elements in [from, to) in ascending order with repetitions,
+// see OrderedSet.Ascend. The multiset must not be modified during the iteration.
+func (ms *OrderedMultiset[T]) Ascend(from, to T) func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		node, found := ms.tree.Ceiling(ms.first(from))
+		if !found {
+			return
+		}
+		for it := ms.tree.IteratorAt(node); ms.comparator(it.Key().value, to) < 0; {
+			if !yield(it.Key().value) || !it.Next() {
+				return
+			}
+		}
+	}
+}
+
+// Descend returns a sequence of elements in (to, from] in descending order with repetitions.
+func (ms *OrderedMultiset[T]) Descend(from, to T) func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		node, found := ms.tree.Floor(ms.last(from))
+		if !found {
+			return
+		}
+		for it := ms.tree.IteratorAt(node); ms.comparator(it.Key().value, to) > 0; {
+			if !yield(it.Key().value) || !it.Prev() {
+				return
+			}
+		}
+	}
+}
+
+// String returns a string representation of container
+func (ms *OrderedMultiset[T]) String() string {
+	items := []string{}
+	for _, v := range ms.Values() {
+		items = append(items, fmt.Sprintf("%v", v))
+	}
+	return "TreeMultiset\n" + strings.Join(items, ", ")
+}
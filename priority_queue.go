@@ -0,0 +1,247 @@
+package main
+
+import (
+	"cmp"
+)
+
+// PriorityQueue is a binary heap. Top is the smallest element according to the comparator,
+// pass a reversed comparator to get a max-heap (like std::priority_queue in c++).
+type PriorityQueue[T any] struct {
+	data       []T
+	comparator RBComparator[T]
+}
+
+// NewPriorityQueue instantiates a min-heap with the natural order, values are heapified in O(n).
+func NewPriorityQueue[T cmp.Ordered](values ...T) *PriorityQueue[T] {
+	return NewPriorityQueueWith[T](cmp.Compare[T], values...)
+}
+
+// NewPriorityQueueWith instantiates a heap with the custom comparator, values are copied and heapified in O(n).
+func NewPriorityQueueWith[T any](comparator RBComparator[T], values ...T) *PriorityQueue[T] {
+	pq := &PriorityQueue[T]{data: append([]T(nil), values...), comparator: comparator}
+	for i := len(pq.data)/2 - 1; i >= 0; i-- {
+		pq.down(i)
+	}
+	return pq
+}
+
+// Push adds the values (one or more) to the queue in O(log n) each.
+func (pq *PriorityQueue[T]) Push(values ...T) {
+	for _, v := range values {
+		pq.data = append(pq.data, v)
+		pq.up(len(pq.data) - 1)
+	}
+}
+
+// Pop removes and returns the top element. Panics if the queue is empty.
+func (pq *PriorityQueue[T]) Pop() T {
+	if len(pq.data) == 0 {
+		panic("PriorityQueue: Pop from empty queue")
+	}
+	top := pq.data[0]
+	last := len(pq.data) - 1
+	pq.data[0] = pq.data[last]
+	var zero T
+	pq.data[last] = zero
+	pq.data = pq.data[:last]
+	if last > 0 {
+		pq.down(0)
+	}
+	return top
+}
+
+// Top returns the top element without removing it. Panics if the queue is empty.
+func (pq *PriorityQueue[T]) Top() T {
+	if len(pq.data) == 0 {
+		panic("PriorityQueue: Top of empty queue")
+	}
+	return pq.data[0]
+}
+
+// Len returns number of elements within the queue.
+func (pq *PriorityQueue[T]) Len() int {
+	return len(pq.data)
+}
+
+// Empty returns true if queue does not contain any elements.
+func (pq *PriorityQueue[T]) Empty() bool {
+	return len(pq.data) == 0
+}
+
+// Clear removes all elements from the queue.
+func (pq *PriorityQueue[T]) Clear() {
+	pq.data = pq.data[:0]
+}
+
+// Values returns all elements in heap order (not sorted).
+func (pq *PriorityQueue[T]) Values() []T {
+	return append([]T(nil), pq.data...)
+}
+
+func (pq *PriorityQueue[T]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if pq.comparator(pq.data[i], pq.data[parent]) >= 0 {
+			break
+		}
+		pq.data[i], pq.data[parent] = pq.data[parent], pq.data[i]
+		i = parent
+	}
+}
+
+func (pq *PriorityQueue[T]) down(i int) {
+	n := len(pq.data)
+	for {
+		smallest := i
+		if l := 2*i + 1; l < n && pq.comparator(pq.data[l], pq.data[smallest]) < 0 {
+			smallest = l
+		}
+		if r := 2*i + 2; r < n && pq.comparator(pq.data[r], pq.data[smallest]) < 0 {
+			smallest = r
+		}
+		if smallest == i {
+			return
+		}
+		pq.data[i], pq.data[smallest] = pq.data[smallest], pq.data[i]
+		i = smallest
+	}
+}
+
+// PQItem is an element of IndexedPriorityQueue, the handle returned by Push
+// can be used to change the element's value or remove it from the queue.
+type PQItem[T any] struct {
+	Value T
+	index int // position in the heap, -1 if the item is not in the queue
+}
+
+// InQueue returns true if the item has not been popped or removed yet.
+func (item *PQItem[T]) InQueue() bool {
+	return item.index >= 0
+}
+
+// IndexedPriorityQueue is a binary heap with handles supporting decrease-key and removal in O(log n),
+// e.g. for Dijkstra without duplicate entries. Top is the smallest element according to the comparator.
+type IndexedPriorityQueue[T any] struct {
+	items      []*PQItem[T]
+	comparator RBComparator[T]
+}
+
+// NewIndexedPriorityQueue instantiates an empty indexed min-heap with the natural order.
+func NewIndexedPriorityQueue[T cmp.Ordered]() *IndexedPriorityQueue[T] {
+	return NewIndexedPriorityQueueWith[T](cmp.Compare[T])
+}
+
+// NewIndexedPriorityQueueWith instantiates an empty indexed heap with the custom comparator.
+func NewIndexedPriorityQueueWith[T any](comparator RBComparator[T]) *IndexedPriorityQueue[T] {
+	return &IndexedPriorityQueue[T]{comparator: comparator}
+}
+
+// Push adds the value to the queue and returns its handle.
+func (pq *IndexedPriorityQueue[T]) Push(value T) *PQItem[T] {
+	item := &PQItem[T]{Value: value, index: len(pq.items)}
+	pq.items = append(pq.items, item)
+	pq.up(item.index)
+	return item
+}
+
+// Pop removes and returns the top item. Panics if the queue is empty.
+func (pq *IndexedPriorityQueue[T]) Pop() *PQItem[T] {
+	if len(pq.items) == 0 {
+		panic("IndexedPriorityQueue: Pop from empty queue")
+	}
+	top := pq.items[0]
+	pq.Remove(top)
+	return top
+}
+
+// Top returns the top item without removing it. Panics if the queue is empty.
+func (pq *IndexedPriorityQueue[T]) Top() *PQItem[T] {
+	if len(pq.items) == 0 {
+		panic("IndexedPriorityQueue: Top of empty queue")
+	}
+	return pq.items[0]
+}
+
+// Update changes the value of the item which is still in the queue (decrease-key or increase-key).
+func (pq *IndexedPriorityQueue[T]) Update(item *PQItem[T], value T) {
+	if !item.InQueue() {
+		panic("IndexedPriorityQueue: Update of item not in queue")
+	}
+	item.Value = value
+	pq.fix(item.index)
+}
+
+// Remove removes the item from the queue, does nothing if it was already removed.
+func (pq *IndexedPriorityQueue[T]) Remove(item *PQItem[T]) {
+	if !item.InQueue() {
+		return
+	}
+	i, last := item.index, len(pq.items)-1
+	if i != last {
+		pq.swap(i, last)
+	}
+	pq.items[last] = nil
+	pq.items = pq.items[:last]
+	item.index = -1
+	if i != last {
+		pq.fix(i)
+	}
+}
+
+// Len returns number of elements within the queue.
+func (pq *IndexedPriorityQueue[T]) Len() int {
+	return len(pq.items)
+}
+
+// Empty returns true if queue does not contain any elements.
+func (pq *IndexedPriorityQueue[T]) Empty() bool {
+	return len(pq.items) == 0
+}
+
+func (pq *IndexedPriorityQueue[T]) less(i, j int) bool {
+	return pq.comparator(pq.items[i].Value, pq.items[j].Value) < 0
+}
+
+func (pq *IndexedPriorityQueue[T]) swap(i, j int) {
+	pq.items[i], pq.items[j] = pq.items[j], pq.items[i]
+	pq.items[i].index = i
+	pq.items[j].index = j
+}
+
+// fix restores the heap after the value at i has changed.
+func (pq *IndexedPriorityQueue[T]) fix(i int) {
+	if i > 0 && pq.less(i, (i-1)/2) {
+		pq.up(i)
+	} else {
+		pq.down(i)
+	}
+}
+
+func (pq *IndexedPriorityQueue[T]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !pq.less(i, parent) {
+			break
+		}
+		pq.swap(i, parent)
+		i = parent
+	}
+}
+
+func (pq *IndexedPriorityQueue[T]) down(i int) {
+	n := len(pq.items)
+	for {
+		smallest := i
+		if l := 2*i + 1; l < n && pq.less(l, smallest) {
+			smallest = l
+		}
+		if r := 2*i + 2; r < n && pq.less(r, smallest) {
+			smallest = r
+		}
+		if smallest == i {
+			return
+		}
+		pq.swap(i, smallest)
+		i = smallest
+	}
+}
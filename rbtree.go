@@ -27,6 +27,7 @@ type RBNode[K comparable, V any] struct {
 	Key    K
 	Value  V
 	color  color
+	size   int // number of nodes in the subtree
 	Left   *RBNode[K, V]
 	Right  *RBNode[K, V]
 	Parent *RBNode[K, V]
@@ -49,7 +50,7 @@ func (tree *RBTree[K, V]) Put(key K, value V) {
 	if tree.Root == nil {
 		// Assert key is of comparator's type for initial tree
 		tree.Comparator(key, key)
-		tree.Root = &RBNode[K, V]{Key: key, Value: value, color: red}
+		tree.Root = &RBNode[K, V]{Key: key, Value: value, color: red, size: 1}
 		insertedNode = tree.Root
 	} else {
 		node := tree.Root
@@ -63,7 +64,7 @@ func (tree *RBTree[K, V]) Put(key K, value V) {
 				return
 			case compare < 0:
 				if node.Left == nil {
-					node.Left = &RBNode[K, V]{Key: key, Value: value, color: red}
+					node.Left = &RBNode[K, V]{Key: key, Value: value, color: red, size: 1}
 					insertedNode = node.Left
 					loop = false
 				} else {
@@ -71,7 +72,7 @@ func (tree *RBTree[K, V]) Put(key K, value V) {
 				}
 			case compare > 0:
 				if node.Right == nil {
-					node.Right = &RBNode[K, V]{Key: key, Value: value, color: red}
+					node.Right = &RBNode[K, V]{Key: key, Value: value, color: red, size: 1}
 					insertedNode = node.Right
 					loop = false
 				} else {
@@ -80,6 +81,9 @@ func (tree *RBTree[K, V]) Put(key K, value V) {
 			}
 		}
 		insertedNode.Parent = node
+		for ; node != nil; node = node.Parent {
+			node.size++
+		}
 	}
 	tree.insertCase1(insertedNode)
 	tree.size++
@@ -111,12 +115,16 @@ func (tree *RBTree[K, V]) Remove(key K) {
 		return
 	}
 	if node.Left != nil && node.Right != nil {
-		pred := node.Left.maximumNode()
-		node.Key = pred.Key
-		node.Value = pred.Value
-		node = pred
+		// node and its predecessor swap places instead of copying key/value,
+		// so pointers to other nodes (iterators, OrderedMap.Ref) stay valid
+		tree.swapWithPredecessor(node, node.Left.maximumNode())
 	}
 	if node.Left == nil || node.Right == nil {
+		// node is removed from every subtree on the path to the root,
+		// rotations below recompute sizes from children and stay consistent
+		for n := node; n != nil; n = n.Parent {
+			n.size--
+		}
 		if node.Right == nil {
 			child = node.Left
 		} else {
@@ -144,20 +152,69 @@ func (tree *RBTree[K, V]) Size() int {
 	return tree.size
 }
 
-// Size returns the number of elements stored in the subtree.
-// Computed dynamically on each call, i.e. the subtree is traversed to count the number of the nodes.
+// Size returns the number of elements stored in the subtree in O(1), nil node has size 0.
 func (node *RBNode[K, V]) Size() int {
 	if node == nil {
 		return 0
 	}
-	size := 1
-	if node.Left != nil {
-		size += node.Left.Size()
+	return node.size
+}
+
+// KthNode returns the k-th smallest node (0-based) or nil if k is out of range, O(log n).
+func (tree *RBTree[K, V]) KthNode(k int) *RBNode[K, V] {
+	if k < 0 || k >= tree.size {
+		return nil
 	}
-	if node.Right != nil {
-		size += node.Right.Size()
+	node := tree.Root
+	for node != nil {
+		left := node.Left.Size()
+		switch {
+		case k < left:
+			node = node.Left
+		case k == left:
+			return node
+		default:
+			k -= left + 1
+			node = node.Right
+		}
 	}
-	return size
+	return nil
+}
+
+// KthKey returns the k-th smallest key (0-based), second return parameter is false if k is out of range.
+func (tree *RBTree[K, V]) KthKey(k int) (key K, found bool) {
+	if node := tree.KthNode(k); node != nil {
+		return node.Key, true
+	}
+	return key, false
+}
+
+// Rank returns the number of keys strictly less than the given key, O(log n).
+// The key does not have to be in the tree.
+func (tree *RBTree[K, V]) Rank(key K) int {
+	rank := 0
+	node := tree.Root
+	for node != nil {
+		if tree.Comparator(key, node.Key) <= 0 {
+			node = node.Left
+		} else {
+			rank += node.Left.Size() + 1
+			node = node.Right
+		}
+	}
+	return rank
+}
+
+// CountInRange returns the number of keys in [lo, hi] (both inclusive), O(log n).
+func (tree *RBTree[K, V]) CountInRange(lo, hi K) int {
+	if tree.Comparator(lo, hi) > 0 {
+		return 0
+	}
+	count := tree.Rank(hi) - tree.Rank(lo)
+	if tree.lookup(hi) != nil {
+		count++
+	}
+	return count
 }
 
 // Keys returns all keys in-order
@@ -260,6 +317,36 @@ func (tree *RBTree[K, V]) Ceiling(key K) (ceiling *RBNode[K, V], found bool) {
 	return nil, false
 }
 
+// Higher finds the smallest node strictly greater than the given key.
+// Second return parameter is true if such node was found, otherwise false.
+func (tree *RBTree[K, V]) Higher(key K) (higher *RBNode[K, V], found bool) {
+	node := tree.Root
+	for node != nil {
+		if tree.Comparator(key, node.Key) < 0 {
+			higher, found = node, true
+			node = node.Left
+		} else {
+			node = node.Right
+		}
+	}
+	return higher, found
+}
+
+// Lower finds the largest node strictly smaller than the given key.
+// Second return parameter is true if such node was found, otherwise false.
+func (tree *RBTree[K, V]) Lower(key K) (lower *RBNode[K, V], found bool) {
+	node := tree.Root
+	for node != nil {
+		if tree.Comparator(key, node.Key) > 0 {
+			lower, found = node, true
+			node = node.Right
+		} else {
+			node = node.Left
+		}
+	}
+	return lower, found
+}
+
 // Clear removes all nodes from the tree.
 func (tree *RBTree[K, V]) Clear() {
 	tree.Root = nil
@@ -356,6 +443,8 @@ func (tree *RBTree[K, V]) rotateLeft(node *RBNode[K, V]) {
 	}
 	right.Left = node
 	node.Parent = right
+	node.updateSize()
+	right.updateSize()
 }
 
 func (tree *RBTree[K, V]) rotateRight(node *RBNode[K, V]) {
@@ -367,6 +456,38 @@ func (tree *RBTree[K, V]) rotateRight(node *RBNode[K, V]) {
 	}
 	left.Right = node
 	node.Parent = left
+	node.updateSize()
+	left.updateSize()
+}
+
+// swapWithPredecessor exchanges positions (links, color and subtree size) of the node with two children
+// and its in-order predecessor, after that the node has no right child and can be unlinked.
+func (tree *RBTree[K, V]) swapWithPredecessor(node, pred *RBNode[K, V]) {
+	node.color, pred.color = pred.color, node.color
+	node.size, pred.size = pred.size, node.size
+
+	predParent, predLeft := pred.Parent, pred.Left
+	tree.replaceNode(node, pred)
+	pred.Right = node.Right
+	pred.Right.Parent = pred
+	if predParent == node {
+		pred.Left = node
+		node.Parent = pred
+	} else {
+		pred.Left = node.Left
+		pred.Left.Parent = pred
+		predParent.Right = node
+		node.Parent = predParent
+	}
+	node.Left = predLeft
+	if predLeft != nil {
+		predLeft.Parent = node
+	}
+	node.Right = nil
+}
+
+func (node *RBNode[K, V]) updateSize() {
+	node.size = node.Left.Size() + node.Right.Size() + 1
 }
 
 func (tree *RBTree[K, V]) replaceNode(old *RBNode[K, V], new *RBNode[K, V]) {
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// checkSizes recounts every subtree and compares it with the maintained node.size
+func checkSizes[K comparable, V any](t *testing.T, node *RBNode[K, V]) int {
+	if node == nil {
+		return 0
+	}
+	for _, child := range []*RBNode[K, V]{node.Left, node.Right} {
+		if child != nil && child.Parent != node {
+			t.Fatalf("node %v: child %v has wrong parent", node.Key, child.Key)
+		}
+	}
+	size := checkSizes(t, node.Left) + checkSizes(t, node.Right) + 1
+	if node.size != size {
+		t.Fatalf("node %v: size %d, recounted %d", node.Key, node.size, size)
+	}
+	return size
+}
+
+func TestRBTreeSubtreeSizes(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 200; iter++ {
+		tree := NewRBT[int, int]()
+		keys := make(map[int]bool)
+		for op := 0; op < 200; op++ {
+			key := r.Intn(50)
+			if r.Intn(3) == 0 {
+				tree.Remove(key)
+				delete(keys, key)
+			} else {
+				tree.Put(key, op)
+				keys[key] = true
+			}
+
+			if got := checkSizes(t, tree.Root); got != len(keys) || tree.Size() != len(keys) {
+				t.Fatalf("tree has %d nodes (Size %d), want %d", got, tree.Size(), len(keys))
+			}
+			if tree.Root != nil && tree.Root.Parent != nil {
+				t.Fatalf("root has a parent")
+			}
+			for k, key := range tree.Keys() {
+				if rank := tree.Rank(key); rank != k {
+					t.Fatalf("Rank(%d) = %d, want %d", key, rank, k)
+				}
+				if got, _ := tree.KthKey(k); got != key {
+					t.Fatalf("KthKey(%d) = %d, want %d", k, got, key)
+				}
+			}
+		}
+	}
+}
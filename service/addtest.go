@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"playground/service/tasks"
+)
+
+const (
+	reset = "\033[0m"
+	red   = "\033[31m"
+	green = "\033[32m"
+	bold  = "\033[1m"
+)
+
+var (
+	testName = flag.String("name", "", "test name (default: next free number)")
+	inFile   = flag.String("in", "", "read input from file instead of stdin")
+	ansFile  = flag.String("ans", "", "read answer from file instead of stdin")
+)
+
+// Добавляет свой тест задачи в tests/<letter>/<name>.in и <name>.ans.
+// Вход и ответ читаются из файлов или вводятся в терминале (конец ввода - Ctrl+D),
+// пустой ответ - тест без ответа: тестер только запустит решение и покажет вывод.
+func main() {
+	flag.Parse()
+	if flag.NArg() < 1 {
+		fmt.Println("Usage: addtest [-name name] [-in file] [-ans file] <task_letter | task_dir>")
+		os.Exit(1)
+	}
+	task := tasks.Resolve(flag.Arg(0))
+
+	dir := task.CustomDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		panic(err)
+	}
+
+	name := *testName
+	if name == "" {
+		name = nextName(dir)
+	}
+	in := filepath.Join(dir, name+".in")
+	ans := filepath.Join(dir, name+".ans")
+	if fileExists(in) {
+		fmt.Printf(red+"Test %s already exists"+reset+"\n", in)
+		os.Exit(1)
+	}
+
+	input := read(*inFile, "Input (Ctrl+D to finish):")
+	if strings.TrimSpace(input) == "" {
+		fmt.Println(red + "Empty input, nothing to save" + reset)
+		os.Exit(1)
+	}
+	answer := ""
+	if *inFile == "" || *ansFile != "" {
+		answer = read(*ansFile, "Expected answer (empty - run only, Ctrl+D to finish):")
+	}
+
+	if err := os.WriteFile(in, []byte(input), 0644); err != nil {
+		panic(err)
+	}
+	if strings.TrimSpace(answer) == "" {
+		fmt.Printf(green+"Saved %s (no answer)"+reset+"\n", in)
+		return
+	}
+	if err := os.WriteFile(ans, []byte(answer), 0644); err != nil {
+		panic(err)
+	}
+	fmt.Printf(green+"Saved %s, %s"+reset+"\n", in, ans)
+}
+
+// read читает файл или, если путь не задан, stdin до EOF
+func read(path, prompt string) string {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			panic(err)
+		}
+		return string(data)
+	}
+
+	fmt.Println(bold + prompt + reset)
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		panic(err)
+	}
+	text := string(data)
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return text
+}
+
+// nextName - первый свободный номер теста: 1, 2, ...
+func nextName(dir string) string {
+	for i := 1; ; i++ {
+		name := strconv.Itoa(i)
+		if !fileExists(filepath.Join(dir, name+".in")) {
+			return name
+		}
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -1,255 +1,657 @@
 package main
 
 import (
-	"bufio"
+	"bytes"
+	"flag"
 	"fmt"
 	"go/ast"
+	"go/format"
+	"go/importer"
 	"go/parser"
+	"go/printer"
 	"go/token"
+	"go/types"
+	"maps"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/samber/lo"
 )
 
-type SrcFile struct {
-	Name         string
-	Dependencies []string
+// SourceFile - разобранный исходник: библиотечный файл из корня репозитория или решение
+type SourceFile struct {
+	Name    string
+	Decls   []*Decl
+	Imports []Import
+}
+
+// Decl - top-level объявление: функция, метод, тип или группа var/const
+type Decl struct {
+	Names []string // объявленные имена, для методов - пусто
+	Recv  string   // тип-получатель, только для методов
+	Name  string   // имя функции или метода
+	// Idents - используемые идентификаторы, Selected - имена после точки (x.Sel): методы, поля
+	Idents   map[string]bool
+	Selected map[string]bool
+	Text     string
+	Line     int // строка начала Text в исходном файле
+	Used     bool
+}
+
+type Import struct {
+	Name  string // имя пакета в коде: алиас или последний элемент пути
+	Alias string // алиас из исходника, если есть (в том числе _ и .)
+	Path  string
+	File  string
+	Line  int
+}
+
+// implicitMethods вызываются стандартной библиотекой через интерфейсы (sort, container/heap, error,
+// fmt.Stringer и fmt.Formatter), поэтому в коде может не быть ни одного x.Method для них.
+// Без String/Format fmt молча печатал бы структуру вместо String() - другой вывод без ошибки компиляции
+var implicitMethods = map[string]bool{
+	"Len":    true,
+	"Less":   true,
+	"Swap":   true,
+	"Push":   true,
+	"Pop":    true,
+	"Error":  true,
+	"String": true,
+	"Format": true,
 }
 
 const (
-	inout      = "./inout.go"
-	set        = "./set.go"
-	multiset   = "./multiset.go"
-	orderedSet = "./set_ordered.go"
-	math       = "./math.go"
-
-	// only deps
-	rbtree = "./rbtree.go"
+	reset  = "\033[0m"
+	red    = "\033[31m"
+	yellow = "\033[33m"
+	bold   = "\033[1m"
 )
 
-var (
-	sourceFiles = []SrcFile{
-		{
-			Name: inout,
-		},
-		{
-			Name: set,
-		},
-		{
-			Name: multiset,
-		},
-		{
-			Name: orderedSet,
-			Dependencies: []string{
-				rbtree,
-			},
-		},
-		{
-			Name: math,
-		},
-	}
-)
+var output = flag.String("o", "", "merged file path (default: solutions/ of the task)")
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: go run merger.go <output-file>")
+	flag.Parse()
+	if flag.NArg() < 1 {
+		fmt.Println("Usage: go run merger.go [-o merged.go] <task-letter | task-dir | source-name>")
 		return
 	}
-	taskLetter := os.Args[1]
-	outFile := "./solutions/task_" + taskLetter + ".go"
+	solutionFile, outFile := resolvePaths(flag.Arg(0))
+	if *output != "" {
+		outFile = *output
+	}
 
-	files := getSrcFilesList(taskLetter)
+	solution, err := parseSourceFile(solutionFile)
+	if err != nil {
+		fail(err)
+	}
+	idents, selected := make(map[string]bool), make(map[string]bool)
+	for _, decl := range solution.Decls {
+		decl.Used = true
+		maps.Copy(idents, decl.Idents)
+		maps.Copy(selected, decl.Selected)
+	}
 
-	var builder strings.Builder
-	builder.WriteString("package main\n\n")
+	libs := loadLibFiles(solutionFile)
+	markUsedDecls(libs, idents, selected)
 
-	importsSet := make(map[string]struct{})
-	var codeParts []string
+	var (
+		imports    []Import
+		chunks     []Chunk
+		declaredIn = make(map[string]string) // top-level имя -> файл, где оно объявлено
+	)
 
-	for i, file := range files {
-		f, err := os.Open(file)
-		if err != nil {
-			panic(err)
+	for _, file := range append(libs, solution) {
+		var fileChunks []Chunk
+		for _, decl := range file.Decls {
+			if !decl.Used {
+				continue
+			}
+			fileChunks = append(fileChunks, Chunk{File: file.Name, Line: decl.Line, Text: decl.Text})
+			for _, name := range decl.Names {
+				declaredIn[name] = file.Name
+			}
 		}
 
-		scanner := bufio.NewScanner(f)
-		var codeLines []string
-		inImports := false
-
-		if i == len(files)-1 {
-			// последний файл - это файл с решением
+		if file.Name == solution.Name {
+			// последний файл - это файл с решением, он идет целиком после разделителя
 			solutionDividerLine := fmt.Sprintf(
 				"// %s solution %s",
 				strings.Repeat("=", 50),
 				strings.Repeat("=", 50),
 			)
-			codeLines = append(codeLines, solutionDividerLine, "")
+			fileChunks = append([]Chunk{{Text: solutionDividerLine}}, fileChunks...)
+		} else {
+			if len(fileChunks) == 0 {
+				continue
+			}
+			fmt.Printf("File %s: %d of %d declarations used\n", file.Name, len(fileChunks), len(file.Decls))
 		}
+		imports = append(imports, file.Imports...)
+		chunks = append(chunks, fileChunks...)
+	}
 
-		for scanner.Scan() {
-			line := scanner.Text()
+	// Одно имя для разных пакетов проверяем до выкидывания импортов: go/types связал бы оба имени
+	// с одним импортом, и алиас решения выкинулся бы как неиспользуемый с непонятной ошибкой undefined
+	imports = lo.UniqBy(imports, Import.spec)
+	if err := checkImportNames(imports); err != nil {
+		fail(err)
+	}
+	// импорты, нужные только выкинутым объявлениям, убираем, иначе будет "imported and not used"
+	imports = pruneImports(imports, chunks)
+	imports, err = mergeImports(imports, declaredIn)
+	if err != nil {
+		fail(err)
+	}
 
-			// Skip package main
-			if strings.HasPrefix(line, "package main") {
-				continue
-			}
+	// Проверяем типы до записи. В проверяемом варианте перед каждым объявлением стоит //line директива,
+	// поэтому ошибки указывают на исходный файл и строку, а не на смерженный файл
+	checkSrc := render(imports, chunks, true)
+	if errs := typeCheck(checkSrc); len(errs) > 0 {
+		fail(fmt.Errorf("merged file does not compile:\n\t%s", strings.Join(errs, "\n\t")))
+	}
 
-			// Collect imports
-			if strings.HasPrefix(line, "import") {
-				inImports = true
-				if strings.Contains(line, "(") {
-					continue // skip multi-import line start 'import "math"'
-				} else {
-					// single import
-					importLine := strings.TrimSpace(line[6:])
-					importLine = strings.Trim(importLine, `" `)
-					importsSet[importLine] = struct{}{}
-					inImports = false
-					continue
-				}
-			}
+	merged, err := format.Source([]byte(render(imports, chunks, false)))
+	if err != nil {
+		fail(fmt.Errorf("format merged file: %w", err))
+	}
 
-			// Handle multi-line imports
-			if inImports {
-				if strings.Contains(line, ")") {
-					inImports = false
-					continue
-				}
-				importLine := strings.TrimSpace(line)
-				importLine = strings.Trim(importLine, `"`)
-				if importLine != "" {
-					importsSet[importLine] = struct{}{}
-				}
-				continue
-			}
+	// Получаем директорию из пути
+	dir := filepath.Dir(outFile)
 
-			codeLines = append(codeLines, line)
-		}
+	// Создаём директорию и все недостающие родительские директории
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		panic(err)
+	}
 
-		codeParts = append(codeParts, strings.Join(codeLines, "\n"))
-		_ = f.Close()
+	err = os.WriteFile(outFile, merged, 0644)
+	if err != nil {
+		panic(err)
 	}
 
-	// Write collected imports
-	if len(importsSet) > 0 {
-		importsArr := make([]string, 0, len(importsSet))
-		for importLine := range importsSet {
-			importsArr = append(importsArr, importLine)
+	vet(checkSrc)
+
+	fmt.Printf(bold+"%s\n"+reset, strings.TrimSuffix(filepath.Base(outFile), ".go"))
+}
+
+// Chunk - кусок итогового файла и его место в исходнике
+type Chunk struct {
+	File string
+	Line int
+	Text string
+}
+
+// render собирает итоговый файл. С lineDirectives перед каждым куском ставится //line директива
+// на исходный файл, такой вариант нужен только для проверки типов и vet
+func render(imports []Import, chunks []Chunk, lineDirectives bool) string {
+	var builder strings.Builder
+	builder.WriteString("package main\n\n")
+
+	directive := func(file string, line int) {
+		if lineDirectives && file != "" {
+			builder.WriteString(fmt.Sprintf("//line %s:%d:1\n", absPath(file), line))
 		}
-		sort.Strings(importsArr)
+	}
 
+	// Write collected imports
+	if len(imports) > 0 {
 		builder.WriteString("import (\n")
-		for _, imp := range importsArr {
-			builder.WriteString(fmt.Sprintf("\t\"%s\"\n", imp))
+		for _, imp := range imports {
+			directive(imp.File, imp.Line)
+			builder.WriteString("\t" + imp.spec() + "\n")
 		}
 		builder.WriteString(")\n\n")
 	}
 
 	// Write code parts
-	for _, part := range codeParts {
-		builder.WriteString(strings.TrimSpace(part))
+	for _, chunk := range chunks {
+		directive(chunk.File, chunk.Line)
+		builder.WriteString(strings.TrimSpace(chunk.Text))
 		builder.WriteString("\n\n")
 	}
 
-	// Получаем директорию из пути
-	dir := filepath.Dir(outFile)
+	return builder.String()
+}
 
-	// Создаём директорию и все недостающие родительские директории
-	err := os.MkdirAll(dir, 0755)
+// typeCheck проверяет смерженный файл через go/types и возвращает ошибки с позициями в исходниках
+func typeCheck(src string) []string {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, "merged.go", src, parser.AllErrors)
 	if err != nil {
-		panic(err)
+		return []string{relPaths(err.Error())}
+	}
+
+	var errs []string
+	conf := types.Config{
+		Importer: importer.Default(),
+		Error: func(err error) {
+			errs = append(errs, relPaths(err.Error()))
+		},
 	}
+	_, _ = conf.Check("main", fset, []*ast.File{node}, nil)
+	return errs
+}
 
-	err = os.WriteFile(outFile, []byte(builder.String()), 0644)
+// vet прогоняет go vet по смерженному файлу, замечания только выводятся и не блокируют merge
+func vet(src string) {
+	dir, err := os.MkdirTemp("", "merger")
 	if err != nil {
 		panic(err)
 	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "merged.go")
+	if err := os.WriteFile(file, []byte(src), 0644); err != nil {
+		panic(err)
+	}
+
+	out, err := exec.Command("go", "vet", file).CombinedOutput()
+	if err != nil {
+		fmt.Printf(yellow+"go vet:\n%s"+reset, relPaths(string(out)))
+	}
+}
+
+func absPath(file string) string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return file
+	}
+	return abs
+}
+
+// relPaths заменяет абсолютные пути на пути относительно текущей директории
+func relPaths(s string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return s
+	}
+	return strings.ReplaceAll(s, wd+string(filepath.Separator), "./")
+}
+
+func fail(err error) {
+	fmt.Printf(red+"%v"+reset+"\n", err)
+	os.Exit(1)
+}
+
+// resolvePaths по аргументу возвращает путь до решения и до смерженного файла:
+//   - директория задачи контеста contests/codeforces/1234/C -> contests/codeforces/1234/C/task_C.go
+//   - имя исходника без .go (brute_C, contests/codeforces/1234/C/gen_C, ...), если такой файл есть
+//   - буква задачи C -> task_C.go
+//
+// Смерженный файл кладется в solutions/ рядом с исходником.
+// Исходники могут быть помечены //go:build ignore - парсер разбирает файл напрямую и не смотрит на теги.
+func resolvePaths(arg string) (solutionFile, outFile string) {
+	name := strings.TrimSuffix(arg, ".go")
+	if info, err := os.Stat(name); err == nil && info.IsDir() {
+		name = filepath.Join(name, "task_"+filepath.Base(name))
+	} else if _, err := os.Stat(name + ".go"); err != nil {
+		name = filepath.Join(filepath.Dir(name), "task_"+filepath.Base(name))
+	}
+	dir, base := filepath.Split(name)
+	return filepath.Clean(name + ".go"), filepath.Join(dir, "solutions", base+".go")
+}
+
+// pruneImports оставляет только импорты, которые используются в итоговом коде.
+// Использование определяется через go/types, поэтому локальные переменные с именем пакета не мешают.
+func pruneImports(imports []Import, chunks []Chunk) []Import {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, "merged.go", render(imports, chunks, false), 0)
+	if err != nil {
+		// синтаксическую ошибку покажет typeCheck
+		return imports
+	}
+
+	info := &types.Info{
+		Defs:      make(map[*ast.Ident]types.Object),
+		Implicits: make(map[ast.Node]types.Object),
+		Uses:      make(map[*ast.Ident]types.Object),
+	}
+	conf := types.Config{Importer: importer.Default(), Error: func(error) {}}
+	_, _ = conf.Check("main", fset, []*ast.File{node}, info)
+
+	used := make(map[types.Object]bool)
+	for _, obj := range info.Uses {
+		if pkgName, ok := obj.(*types.PkgName); ok {
+			used[pkgName] = true
+		}
+	}
+
+	var res []Import
+	for i, spec := range node.Imports {
+		imp := imports[i]
+
+		var obj types.Object
+		if spec.Name != nil {
+			obj = info.Defs[spec.Name]
+		} else {
+			obj = info.Implicits[spec]
+		}
+		// _ и . импорты, а также те, что не удалось разрешить, не трогаем
+		if imp.Alias == "_" || imp.Alias == "." || obj == nil || used[obj] {
+			res = append(res, imp)
+		}
+	}
+	return res
+}
+
+// checkImportNames проверяет, что одно имя не используется для разных пакетов
+func checkImportNames(imports []Import) error {
+	var (
+		byName = make(map[string]Import)
+		errs   []string
+	)
+	for _, imp := range imports {
+		if imp.Name == "_" || imp.Name == "." {
+			continue
+		}
+		if other, ok := byName[imp.Name]; ok && other.Path != imp.Path {
+			errs = append(errs, fmt.Sprintf(
+				"import name %s is used for %q (%s) and %q (%s)",
+				imp.Name, other.Path, other.File, imp.Path, imp.File,
+			))
+			continue
+		}
+		byName[imp.Name] = imp
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("import conflicts:\n\t%s", strings.Join(errs, "\n\t"))
+	}
+	return nil
+}
 
-	const (
-		reset = "\033[0m"
-		bold  = "\033[1m"
+// mergeImports убирает дубликаты и проверяет, что имя пакета не совпадает с top-level идентификатором
+// из решения или библиотеки. Конфликты имен между импортами проверяет checkImportNames до pruneImports
+func mergeImports(imports []Import, declaredIn map[string]string) ([]Import, error) {
+	var (
+		res  []Import
+		seen = make(map[string]bool)
+		errs []string
 	)
 
-	fmt.Printf(bold+"task_%s\n"+reset, os.Args[1])
+	for _, imp := range imports {
+		if seen[imp.spec()] {
+			continue
+		}
+		seen[imp.spec()] = true
+
+		if imp.Name != "_" && imp.Name != "." {
+			if file, ok := declaredIn[imp.Name]; ok {
+				errs = append(errs, fmt.Sprintf(
+					"package %q imported as %s (%s) clashes with identifier %s declared in %s",
+					imp.Path, imp.Name, imp.File, imp.Name, file,
+				))
+				continue
+			}
+		}
+		res = append(res, imp)
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("import conflicts:\n\t%s", strings.Join(errs, "\n\t"))
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Path != res[j].Path {
+			return res[i].Path < res[j].Path
+		}
+		return res[i].Alias < res[j].Alias
+	})
+	return res, nil
 }
 
-func getSrcFilesList(taskLetter string) []string {
-	solutionFileName := fmt.Sprintf("./task_%s.go", taskLetter)
-	used := collectUsedNames(solutionFileName)
+func (imp Import) spec() string {
+	if imp.Alias != "" {
+		return imp.Alias + " " + strconv.Quote(imp.Path)
+	}
+	return strconv.Quote(imp.Path)
+}
+
+// markUsedDecls помечает объявления, достижимые из решения: функции, типы, var/const по имени,
+// методы - если достижим их тип и метод где-то вызывается (x.Method) или вызывается неявно,
+// init - если из файла используется хоть что-то. Повторяем, пока находятся новые объявления.
+func markUsedDecls(libs []SourceFile, idents, selected map[string]bool) {
+	reachedTypes := make(map[string]bool)
+
+	use := func(decl *Decl) {
+		decl.Used = true
+		maps.Copy(idents, decl.Idents)
+		maps.Copy(selected, decl.Selected)
+		for _, name := range decl.Names {
+			reachedTypes[name] = true
+		}
+	}
 
-	filesSet := make(map[string]struct{})
+	for changed := true; changed; {
+		changed = false
+		for _, lib := range libs {
+			fileUsed := lo.SomeBy(lib.Decls, func(decl *Decl) bool { return decl.Used })
 
-	for _, file := range sourceFiles {
-		declared := collectDeclaredNames(file.Name)
+			for _, decl := range lib.Decls {
+				if decl.Used {
+					continue
+				}
 
-		for name := range declared {
-			if used[name] {
-				fmt.Printf("File %s: symbol %s is used in task_A.go\n", file, name)
-				filesSet[file.Name] = struct{}{}
-				for _, dep := range file.Dependencies {
-					filesSet[dep] = struct{}{}
+				var reached bool
+				switch {
+				case decl.Recv != "":
+					reached = reachedTypes[decl.Recv] && (selected[decl.Name] || implicitMethods[decl.Name])
+				case decl.Name == "init":
+					reached = fileUsed
+				default:
+					reached = lo.SomeBy(decl.Names, func(name string) bool { return idents[name] })
+				}
+
+				if reached {
+					use(decl)
+					changed = true
 				}
-				break
 			}
 		}
 	}
-	result := lo.Keys(filesSet)
-	result = append(result, solutionFileName)
-
-	return result
 }
 
-// collectUsedNames parses task_X.go and returns all used identifiers
-func collectUsedNames(path string) map[string]bool {
-	fset := token.NewFileSet()
-	node, err := parser.ParseFile(fset, path, nil, parser.AllErrors)
+// loadLibFiles находит все библиотечные файлы: .go файлы в корне без func main,
+// т.е. все, кроме решений, генераторов, чекеров и т.п.
+func loadLibFiles(solutionFileName string) []SourceFile {
+	paths, err := filepath.Glob("./*.go")
 	if err != nil {
 		panic(err)
 	}
 
-	used := make(map[string]bool)
-
-	ast.Inspect(node, func(n ast.Node) bool {
-		if ident, ok := n.(*ast.Ident); ok {
-			used[ident.Name] = true
+	var libs []SourceFile
+	for _, path := range paths {
+		if path == filepath.Clean(solutionFileName) || strings.HasSuffix(path, "_test.go") {
+			continue
 		}
-		return true
-	})
-
-	fmt.Printf("Used in %v: \t\t%+v\n", path, used)
+		path = "./" + path
 
-	return used
+		file, err := parseSourceFile(path)
+		if err != nil {
+			fmt.Printf("Skip %s: %v\n", path, err)
+			continue
+		}
+		if lo.SomeBy(file.Decls, func(decl *Decl) bool { return decl.Recv == "" && decl.Name == "main" }) {
+			continue
+		}
+		libs = append(libs, file)
+	}
+	return libs
 }
 
-// collectDeclaredNames returns top-level function and type names declared in the given file
-func collectDeclaredNames(path string) map[string]bool {
+// parseSourceFile разбирает файл на импорты и top-level объявления,
+// текст объявлений печатается через go/printer вместе с комментариями
+func parseSourceFile(filename string) (SourceFile, error) {
 	fset := token.NewFileSet()
-	node, err := parser.ParseFile(fset, path, nil, parser.AllErrors)
+	node, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
 	if err != nil {
-		panic(err)
+		return SourceFile{}, err
 	}
 
-	declared := make(map[string]bool)
+	file := SourceFile{Name: filename}
+	for _, imp := range node.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			return SourceFile{}, err
+		}
+		res := Import{
+			Name: path.Base(importPath),
+			Path: importPath,
+			File: filename,
+			Line: fset.Position(imp.Pos()).Line,
+		}
+		if imp.Name != nil {
+			res.Name = imp.Name.Name
+			res.Alias = imp.Name.Name
+		}
+		file.Imports = append(file.Imports, res)
+	}
 
+	prevEnd := node.Name.End()
 	for _, decl := range node.Decls {
-		switch d := decl.(type) {
-		case *ast.FuncDecl:
-			if d.Recv == nil { // только функции, не методы
-				declared[d.Name.Name] = true
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			prevEnd = gen.End()
+			continue
+		}
+		d, err := newDecl(fset, node, decl)
+		if err != nil {
+			return SourceFile{}, err
+		}
+		d.Line = fset.Position(declStart(decl)).Line
+		// комментарии между объявлениями не привязаны ни к одному из них, сохраняем их перед следующим,
+		// сохраняя отступ в строках, чтобы номера строк совпадали с исходником
+		if free, start, end := freeComments(fset, node, prevEnd, declStart(decl)); free != "" {
+			d.Text = free + strings.Repeat("\n", d.Line-end) + d.Text
+			d.Line = start
+		}
+		prevEnd = decl.End()
+		file.Decls = append(file.Decls, d)
+	}
+	if free, start, _ := freeComments(fset, node, prevEnd, node.FileEnd); free != "" && len(file.Decls) > 0 {
+		last := file.Decls[len(file.Decls)-1]
+		last.Text += strings.Repeat("\n", start-fset.Position(prevEnd).Line) + free
+	}
+	return file, nil
+}
+
+func declStart(decl ast.Decl) token.Pos {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Doc != nil {
+			return d.Doc.Pos()
+		}
+	case *ast.GenDecl:
+		if d.Doc != nil {
+			return d.Doc.Pos()
+		}
+	}
+	return decl.Pos()
+}
+
+// freeComments возвращает комментарии из промежутка (from, to) и номера их первой и последней строки
+func freeComments(fset *token.FileSet, node *ast.File, from, to token.Pos) (text string, start, end int) {
+	var b strings.Builder
+	for _, cg := range node.Comments {
+		if cg.Pos() <= from || cg.End() > to {
+			continue
+		}
+		for _, c := range cg.List {
+			line := fset.Position(c.Pos()).Line
+			if b.Len() == 0 {
+				start = line
+			} else {
+				b.WriteString(strings.Repeat("\n", line-end))
 			}
-		case *ast.GenDecl:
-			for _, spec := range d.Specs {
-				if ts, ok := spec.(*ast.TypeSpec); ok {
-					declared[ts.Name.Name] = true
+			b.WriteString(c.Text)
+			end = fset.Position(c.End()).Line
+		}
+	}
+	return b.String(), start, end
+}
+
+func newDecl(fset *token.FileSet, file *ast.File, decl ast.Decl) (*Decl, error) {
+	d := &Decl{}
+	d.Idents, d.Selected = usedNames(decl)
+
+	switch decl := decl.(type) {
+	case *ast.FuncDecl:
+		d.Name = decl.Name.Name
+		if decl.Recv != nil && len(decl.Recv.List) > 0 {
+			d.Recv = recvTypeName(decl.Recv.List[0].Type)
+		} else if d.Name != "init" {
+			d.Names = []string{d.Name}
+		}
+	case *ast.GenDecl:
+		for _, spec := range decl.Specs {
+			switch sp := spec.(type) {
+			case *ast.TypeSpec:
+				d.Names = append(d.Names, sp.Name.Name)
+			case *ast.ValueSpec:
+				for _, name := range sp.Names {
+					d.Names = append(d.Names, name.Name)
 				}
 			}
 		}
 	}
 
-	fmt.Printf("Declared in %v: \t\t%+v\n", path, declared)
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, &printer.CommentedNode{Node: decl, Comments: file.Comments}); err != nil {
+		return nil, err
+	}
+	d.Text = buf.String()
+	return d, nil
+}
+
+// recvTypeName достает имя типа из получателя метода: *RBTree[K, V] -> RBTree
+func recvTypeName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// usedNames returns identifiers used in the node, names after a dot (x.Sel) are returned separately
+func usedNames(node ast.Node) (idents, selected map[string]bool) {
+	idents = make(map[string]bool)
+	selected = make(map[string]bool)
+
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			selected[n.Sel.Name] = true
+			ast.Inspect(n.X, visit)
+			return false
+		case *ast.FuncDecl:
+			// имя функции - это объявление, а не использование
+			if n.Recv != nil {
+				ast.Inspect(n.Recv, visit)
+			}
+			ast.Inspect(n.Type, visit)
+			if n.Body != nil {
+				ast.Inspect(n.Body, visit)
+			}
+			return false
+		case *ast.Ident:
+			idents[n.Name] = true
+		}
+		return true
+	}
+	ast.Inspect(node, visit)
 
-	return declared
+	return idents, selected
 }
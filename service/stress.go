@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"playground/service/tasks"
+)
+
+const (
+	reset = "\033[0m"
+	red   = "\033[31m"
+	green = "\033[32m"
+	bold  = "\033[1m"
+)
+
+var line = "----------------------------------------------------------------------"
+
+type Test struct {
+	Input  string `json:"input"`
+	Output string `json:"output"`
+}
+
+type Problem struct {
+	Name        string `json:"name"`
+	Group       string `json:"group"`
+	TimeLimit   int    `json:"timeLimit,omitempty"`
+	MemoryLimit int    `json:"memoryLimit,omitempty"`
+	Tests       []Test `json:"tests"`
+}
+
+var (
+	iterations = flag.Int("n", 10000, "max number of generated tests")
+	startSeed  = flag.Int64("seed", 1, "seed of the first generated test")
+	extra      = flag.Int("extra", 100, "seeds to try after the first failure looking for a smaller failing input")
+	runTimeout = flag.Duration("timeout", 5*time.Second, "timeout of a single run")
+)
+
+// Стресс-тестирование: gen_X.go генерирует тест по сиду (os.Args[1]),
+// task_X.go и brute_X.go запускаются на нем, пока ответы не разойдутся.
+// Наименьший найденный падающий тест сохраняется в ./tests как новый тест задачи.
+func main() {
+	flag.Parse()
+	if flag.NArg() < 1 {
+		fmt.Println("Usage: stress [-n iterations] [-seed seed] [-extra n] [-timeout d] <task_letter | task_dir>")
+		os.Exit(1)
+	}
+	task := tasks.Resolve(flag.Arg(0))
+
+	dir, err := os.MkdirTemp("", "stress")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var bins []string
+	for _, name := range []string{"task", "brute", "gen"} {
+		src := task.File(name)
+		bin, err := mergeAndBuild(src, dir)
+		if err != nil {
+			fmt.Printf(red+"%s: %v"+reset+"\n", src, err)
+			os.Exit(1)
+		}
+		bins = append(bins, bin)
+	}
+	solution, brute, gen := bins[0], bins[1], bins[2]
+
+	var (
+		failing  *Test
+		failSeed int64
+		left     = *extra
+	)
+	for i := 0; i < *iterations; i++ {
+		seed := *startSeed + int64(i)
+		fmt.Printf("\rseed %d", seed)
+
+		input, err := run(gen, "", strconv.FormatInt(seed, 10))
+		if err != nil {
+			fmt.Printf("\n"+red+"generator failed: %v"+reset+"\n", err)
+			os.Exit(1)
+		}
+		want, err := run(brute, input)
+		if err != nil {
+			fmt.Printf("\n"+red+"brute failed: %v"+reset+"\n", err)
+			printTest(input, "", "")
+			os.Exit(1)
+		}
+		got, err := run(solution, input)
+		if err != nil {
+			got = err.Error()
+		}
+
+		if err != nil || !sameTokens(want, got) {
+			if failing == nil || len(input) < len(failing.Input) {
+				failing = &Test{Input: input, Output: want}
+				failSeed = seed
+				printTest(input, want, got)
+			}
+		}
+		if failing != nil {
+			if left == 0 {
+				break
+			}
+			left--
+		}
+	}
+	fmt.Println()
+
+	if failing == nil {
+		fmt.Printf(green+"✅ %d tests passed"+reset+"\n", *iterations)
+		return
+	}
+
+	path, err := saveTest(task, failSeed, *failing)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf(red+"❌ failing test (seed %d) saved to %s"+reset+"\n", failSeed, path)
+	os.Exit(1)
+}
+
+// mergeAndBuild прогоняет исходник через merger, чтобы в нем были доступны библиотечные файлы,
+// и собирает бинарник во временную директорию
+func mergeAndBuild(src, dir string) (string, error) {
+	if _, err := os.Stat(src); err != nil {
+		return "", err
+	}
+
+	// go run считает аргументы с суффиксом .go исходниками, поэтому передаем имя без расширения.
+	// Смерженные файлы нужны только для сборки, они кладутся во временную директорию, а не в solutions/
+	merged := filepath.Join(dir, filepath.Base(src))
+	merge := exec.Command("go", "run", "./service/merger.go", "-o", merged, strings.TrimSuffix(src, ".go"))
+	merge.Stderr = os.Stderr
+	if err := merge.Run(); err != nil {
+		return "", fmt.Errorf("merge: %w", err)
+	}
+
+	bin := filepath.Join(dir, strings.TrimSuffix(filepath.Base(src), ".go"))
+	build := exec.Command("go", "build", "-o", bin, merged)
+	build.Stdout = os.Stdout
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		return "", fmt.Errorf("build: %w", err)
+	}
+	return bin, nil
+}
+
+func run(bin, input string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), *runTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Stdin = strings.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return stdout.String(), fmt.Errorf("timeout %s", *runTimeout)
+	}
+	if err != nil {
+		return stdout.String(), fmt.Errorf("%w\n%s", err, stderr.String())
+	}
+	return stdout.String(), nil
+}
+
+func sameTokens(a, b string) bool {
+	x, y := strings.Fields(a), strings.Fields(b)
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// saveTest сохраняет тест в <dir>/tests/<letter>. stress <seed>.json,
+// лимиты берутся из сохраненного json задачи, если он есть
+func saveTest(task tasks.Task, seed int64, test Test) (string, error) {
+	prob := Problem{Name: fmt.Sprintf("%s. stress %d", task.Letter, seed)}
+
+	testsDir := filepath.Join(task.Dir, "tests")
+	files, _ := task.TestFiles()
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var orig Problem
+		if json.Unmarshal(data, &orig) == nil && orig.TimeLimit > 0 {
+			prob.Group = orig.Group
+			prob.TimeLimit = orig.TimeLimit
+			prob.MemoryLimit = orig.MemoryLimit
+			break
+		}
+	}
+	prob.Tests = []Test{test}
+
+	data, err := json.MarshalIndent(prob, "", "    ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(testsDir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(testsDir, prob.Name+".json")
+	return path, os.WriteFile(path, data, 0644)
+}
+
+func printTest(input, want, got string) {
+	fmt.Println()
+	fmt.Println(bold + "Input:" + reset)
+	fmt.Println(line)
+	fmt.Print(input)
+	fmt.Println(line)
+	fmt.Println(bold + "Brute output:" + reset)
+	fmt.Println(line)
+	fmt.Print(want)
+	fmt.Println(line)
+	fmt.Println(bold + "Solution output:" + reset)
+	fmt.Println(line)
+	fmt.Print(got)
+	fmt.Println(line)
+}
@@ -2,38 +2,91 @@ package main
 
 import (
 	"bytes"
+	"cmp"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"text/tabwriter"
+	"text/template"
+
+	"playground/service/tasks"
 )
 
-func template(taskLetter string) string {
-	return fmt.Sprintf(`package main
+var templateName = flag.String("template", "", "solution template: name from ./templates or path to a file (default: <platform>.tmpl, then default.tmpl)")
+
+// defaultTemplate используется, если в ./templates нет ни шаблона платформы, ни default.tmpl.
+// Решение помечено //go:build ignore: в директории задачи нет библиотеки, и без тега go vet ./... и go test
+// падали бы на undefined scanT, а main разных задач не конфликтуют между собой
+const defaultTemplate = `//go:build ignore
+
+package main
 
 func main() {
+{{- if .MultiTest}}
 	// t := 1
 	t := scanT[int]()
+{{- else}}
+	t := 1
+	// t := scanT[int]()
+{{- end}}
 
 	for ; t > 0; t-- {
-		solve%s()
+		solve{{.Letter}}()
 	}
 
 	_ = out.Flush()
 }
 
-func solve%s() {
+func solve{{.Letter}}() {
 
 }
-`, taskLetter, taskLetter)
-}
+`
 
 type TestData struct {
-	Name string `json:"name"`
+	Name        string `json:"name"`
+	Group       string `json:"group"`
+	URL         string `json:"url"`
+	Interactive bool   `json:"interactive"`
+	MemoryLimit int    `json:"memoryLimit"`
+	TimeLimit   int    `json:"timeLimit"`
+	Tests       []Test `json:"tests"`
+	Batch       Batch  `json:"batch"`
+}
+
+type Test struct {
+	Input  string `json:"input"`
+	Output string `json:"output"`
+}
+
+// Batch - при парсинге всего контеста Competitive Companion присылает задачи по одной
+// с общим id и количеством задач в контесте
+type Batch struct {
+	ID   string `json:"id"`
+	Size int    `json:"size"`
+}
+
+// TaskMeta - метаданные задачи, сохраняются рядом с решением в meta.json
+type TaskMeta struct {
+	Name        string `json:"name"`
+	Group       string `json:"group"`
+	URL         string `json:"url"`
+	Platform    string `json:"platform"`
+	Contest     string `json:"contest"`
+	Letter      string `json:"letter"`
+	TimeLimit   int    `json:"timeLimit"`   // ms
+	MemoryLimit int    `json:"memoryLimit"` // MB
+	Interactive bool   `json:"interactive"`
+	MultiTest   bool   `json:"multiTest"` // во входе несколько тестов, первая строка - их количество
 }
 
 func handler(w http.ResponseWriter, r *http.Request) {
@@ -55,64 +108,414 @@ func handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := saveTest(body, testData.Name); err != nil {
-		http.Error(w, "Failed to save test", http.StatusInternalServerError)
+	meta := newTaskMeta(testData)
+	row, err := saveTask(meta, body, testData.Tests)
+	if err != nil {
+		log.Printf("Failed to save %s: %v", testData.Name, err)
+	} else {
+		log.Printf("Saved %s to %s", testData.Name, meta.dir())
+	}
+
+	// упавшая задача тоже попадает в пачку, иначе итоговая таблица контеста никогда не напечатается
+	row.Err = err
+	batches.add(testData.Batch, row)
+	if err != nil {
+		http.Error(w, "Failed to save task", http.StatusInternalServerError)
 		return
 	}
-	if err := createSolutionTemplate(testData.Name); err != nil {
-		http.Error(w, "Failed to create solution template", http.StatusInternalServerError)
+	w.WriteHeader(http.StatusOK)
+}
+
+// Status - что произошло с файлом при сохранении
+type Status string
+
+const (
+	Created   Status = "created"
+	Updated   Status = "updated"
+	Unchanged Status = "unchanged"
+	Kept      Status = "kept"   // шаблон решения уже есть, не трогаем
+	Failed    Status = "failed" // до файла не дошли или не смогли записать, причина в Row.Err
+)
+
+// Row - строка итоговой таблицы по сохраненной задаче
+type Row struct {
+	Meta     TaskMeta
+	Tests    int
+	Test     Status
+	Template Status
+	Err      error
+}
+
+// saveTask сохраняет тесты, meta.json, шаблон решения и обновляет индекс контеста.
+// При парсинге контеста задачи приходят пачкой почти одновременно, поэтому директория задачи
+// и индекс контеста защищены своими мьютексами, а повторная отправка той же задачи ничего не меняет на диске
+func saveTask(meta TaskMeta, body []byte, tests []Test) (Row, error) {
+	dir := meta.dir()
+	row := Row{Meta: meta, Tests: len(tests)}
+
+	unlock := lock(dir)
+	defer unlock()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return row, err
+	}
+	var err error
+	if row.Test, err = saveTest(dir, body, meta.Name); err != nil {
+		return row, fmt.Errorf("test: %w", err)
+	}
+	if err := saveMeta(dir, meta); err != nil {
+		return row, fmt.Errorf("meta: %w", err)
+	}
+	if row.Template, err = createSolutionTemplate(dir, meta); err != nil {
+		return row, fmt.Errorf("template: %w", err)
+	}
+	if err := updateIndex(meta); err != nil {
+		return row, fmt.Errorf("index: %w", err)
+	}
+	return row, nil
+}
+
+var locks sync.Map // путь -> *sync.Mutex
+
+func lock(path string) (unlock func()) {
+	v, _ := locks.LoadOrStore(path, &sync.Mutex{})
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// writeFile пишет файл атомарно (через временный файл и rename) и только если содержимое изменилось
+func writeFile(path string, data []byte) (Status, error) {
+	old, err := os.ReadFile(path)
+	switch {
+	case err == nil && bytes.Equal(old, data):
+		return Unchanged, nil
+	case err != nil && !os.IsNotExist(err):
+		return "", err
+	}
+	existed := err == nil
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+
+	if existed {
+		return Updated, nil
+	}
+	return Created, nil
+}
+
+// IndexEntry - задача в индексе контеста contests/<platform>/<contest>/index.json
+type IndexEntry struct {
+	Letter      string `json:"letter"`
+	Name        string `json:"name"`
+	TimeLimit   int    `json:"timeLimit"`   // ms
+	MemoryLimit int    `json:"memoryLimit"` // MB
+	Interactive bool   `json:"interactive,omitempty"`
+	URL         string `json:"url"`
+}
+
+type Index struct {
+	Platform string       `json:"platform"`
+	Contest  string       `json:"contest"`
+	Group    string       `json:"group"`
+	Problems []IndexEntry `json:"problems"`
+}
+
+// updateIndex добавляет или обновляет задачу в индексе контеста, задачи отсортированы по букве
+func updateIndex(meta TaskMeta) error {
+	path := filepath.Join(filepath.Dir(meta.dir()), "index.json")
+
+	unlock := lock(path)
+	defer unlock()
+
+	index := Index{}
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &index); err != nil {
+			log.Printf("Broken %s, rebuilding: %v", path, err)
+			index = Index{}
+		}
+	}
+	index.Platform, index.Contest, index.Group = meta.Platform, meta.Contest, meta.Group
+
+	entry := IndexEntry{
+		Letter:      meta.Letter,
+		Name:        meta.Name,
+		TimeLimit:   meta.TimeLimit,
+		MemoryLimit: meta.MemoryLimit,
+		Interactive: meta.Interactive,
+		URL:         meta.URL,
+	}
+	found := false
+	for i := range index.Problems {
+		if index.Problems[i].Letter == entry.Letter {
+			index.Problems[i], found = entry, true
+		}
+	}
+	if !found {
+		index.Problems = append(index.Problems, entry)
+	}
+	sort.Slice(index.Problems, func(i, j int) bool { return index.Problems[i].Letter < index.Problems[j].Letter })
+
+	data, err := json.MarshalIndent(index, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = writeFile(path, data)
+	return err
+}
+
+// batches собирает задачи одного парсинга контеста, чтобы напечатать итоговую таблицу,
+// когда придет последняя
+var batches = &batchLog{rows: map[string][]Row{}}
+
+type batchLog struct {
+	mu   sync.Mutex
+	rows map[string][]Row
+}
+
+func (b *batchLog) add(batch Batch, row Row) {
+	if batch.ID == "" || batch.Size <= 1 {
+		printSummary([]Row{row})
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	b.mu.Lock()
+	b.rows[batch.ID] = append(b.rows[batch.ID], row)
+	rows := b.rows[batch.ID]
+	if len(rows) < batch.Size {
+		b.mu.Unlock()
+		return
+	}
+	delete(b.rows, batch.ID)
+	b.mu.Unlock()
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Meta.dir() < rows[j].Meta.dir() })
+	printSummary(rows)
+}
+
+func printSummary(rows []Row) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Dir\tName\tTL\tML\tTests\tJSON\tTemplate")
+	var failed []Row
+	for _, r := range rows {
+		test, template := r.Test, r.Template
+		if r.Err != nil {
+			failed = append(failed, r)
+			test, template = cmp.Or(test, Failed), cmp.Or(template, Failed)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%dms\t%dMB\t%d\t%s\t%s\n",
+			r.Meta.dir(), r.Meta.Name, r.Meta.TimeLimit, r.Meta.MemoryLimit, r.Tests, test, template)
+	}
+	_ = w.Flush()
+	for _, r := range failed {
+		fmt.Printf("%s: %v\n", r.Meta.dir(), r.Err)
+	}
 }
 
 func main() {
+	flag.Parse()
 	http.HandleFunc("/", handler)
 	port := 10043
 	log.Printf("Listening on port %d...", port)
 	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", port), nil))
 }
 
-func saveTest(data []byte, name string) error {
+// dir - директория задачи: contests/<platform>/<contest>/<letter>
+func (m TaskMeta) dir() string {
+	return filepath.Join("contests", m.Platform, m.Contest, m.Letter)
+}
+
+var (
+	// https://codeforces.com/contest/1234/problem/A, https://codeforces.com/gym/100001/problem/A,
+	// https://codeforces.com/problemset/problem/1234/A
+	codeforcesURL = regexp.MustCompile(`/(?:contest|gym)/(\d+)/problem/(\w+)|/problemset/problem/(\d+)/(\w+)`)
+	// https://atcoder.jp/contests/abc300/tasks/abc300_a
+	atcoderURL = regexp.MustCompile(`/contests/([\w-]+)/tasks/[\w-]+_(\w+)`)
+
+	unsafeChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+)
+
+// newTaskMeta определяет платформу, контест и букву задачи по url и group из Competitive Companion.
+// Для неизвестных платформ контестом считается group, буквой - часть названия до первой точки.
+func newTaskMeta(data TestData) TaskMeta {
+	meta := TaskMeta{
+		Name:        data.Name,
+		Group:       data.Group,
+		URL:         data.URL,
+		TimeLimit:   data.TimeLimit,
+		MemoryLimit: data.MemoryLimit,
+		Interactive: data.Interactive,
+		MultiTest:   isMultiTest(data.Tests),
+		Platform:    "other",
+		Contest:     sanitize(data.Group),
+		Letter:      sanitize(extractTaskID(data.Name)),
+	}
+
+	u, err := url.Parse(data.URL)
+	if err == nil && u.Host != "" {
+		host := strings.TrimPrefix(u.Hostname(), "www.")
+		meta.Platform = sanitize(strings.SplitN(host, ".", 2)[0])
+
+		if m := codeforcesURL.FindStringSubmatch(u.Path); m != nil && meta.Platform == "codeforces" {
+			meta.Contest, meta.Letter = m[1]+m[3], m[2]+m[4]
+			if strings.HasPrefix(u.Path, "/gym/") {
+				meta.Contest = "gym" + meta.Contest
+			}
+		} else if m := atcoderURL.FindStringSubmatch(u.Path); m != nil && meta.Platform == "atcoder" {
+			meta.Contest, meta.Letter = m[1], strings.ToUpper(m[2])
+		}
+	}
+
+	if meta.Contest == "" {
+		meta.Contest = "unknown"
+	}
+	if meta.Letter == "" {
+		meta.Letter = "X"
+	}
+	return meta
+}
+
+// sanitize оставляет от строки только то, что можно использовать в имени директории и функции
+func sanitize(s string) string {
+	return strings.Trim(unsafeChars.ReplaceAllString(strings.TrimSpace(s), "_"), "_")
+}
+
+func saveTest(dir string, data []byte, name string) (Status, error) {
 	var prettyJSON bytes.Buffer
 	err := json.Indent(&prettyJSON, data, "", "    ") // 4 пробела для отступа
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	dir := "./tests"
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
+	testsDir := filepath.Join(dir, "tests")
+	if err := os.MkdirAll(testsDir, 0755); err != nil {
+		return "", err
+	}
+
+	// название задачи приходит как есть и может содержать / и прочие недопустимые в имени файла символы
+	filename := sanitize(name)
+	if filename == "" {
+		filename = "tests"
 	}
+	path := filepath.Join(testsDir, filename+".json")
+	return writeFile(path, prettyJSON.Bytes())
+}
 
-	filename := fmt.Sprintf("%s.json", name)
-	path := filepath.Join(dir, filename)
-	return os.WriteFile(path, prettyJSON.Bytes(), 0644)
+func saveMeta(dir string, meta TaskMeta) error {
+	data, err := json.MarshalIndent(meta, "", "    ")
+	if err != nil {
+		return err
+	}
+	_, err = writeFile(filepath.Join(dir, "meta.json"), data)
+	return err
 }
 
-func createSolutionTemplate(taskName string) error {
-	taskID := extractTaskID(taskName)
-	dir := "./"
-	fileName := fmt.Sprintf("task_%s.go", taskID)
+func createSolutionTemplate(dir string, data TaskMeta) (Status, error) {
+	fileName := fmt.Sprintf("task_%s.go", data.Letter)
 	outFile := filepath.Join(dir, fileName)
 
-	// Проверка: если файл уже существует — не перезаписываем
-	if _, err := os.Stat(outFile); err == nil {
-		fmt.Printf("File %s already exists, skipping.\n", outFile)
-		return err
+	src, err := renderTemplate(data)
+	if err != nil {
+		return "", err
 	}
 
-	err := os.WriteFile(outFile, []byte(template(taskID)), 0644)
+	// O_EXCL: если файл уже существует — не перезаписываем
+	f, err := os.OpenFile(outFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if os.IsExist(err) {
+		return Kept, nil
+	}
 	if err != nil {
 		fmt.Printf("Failed to write file %s: %s\n", outFile, err)
-		return err
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(src); err != nil {
+		fmt.Printf("Failed to write file %s: %s\n", outFile, err)
+		return "", err
+	}
+	return Created, f.Close()
+}
+
+// renderTemplate рендерит шаблон решения, в шаблоне доступны поля TaskMeta: {{.Name}}, {{.Letter}}, {{.MultiTest}}, ...
+// Шаблон перечитывается на каждую задачу, так что его можно править, не перезапуская сервер
+func renderTemplate(data TaskMeta) ([]byte, error) {
+	name, text := "default", defaultTemplate
+	if path := templatePath(data.Platform); path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		name, text = path, string(b)
 	}
 
-	return nil
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
+// templatePath выбирает шаблон: -template (имя из ./templates или путь), затем templates/<platform>.tmpl,
+// затем templates/default.tmpl. Пустая строка - встроенный шаблон
+func templatePath(platform string) string {
+	var candidates []string
+	if *templateName != "" {
+		candidates = append(candidates, *templateName, filepath.Join("templates", *templateName+".tmpl"))
+	}
+	candidates = append(candidates,
+		filepath.Join("templates", platform+".tmpl"),
+		filepath.Join("templates", "default.tmpl"),
+	)
+	for _, path := range candidates {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	if *templateName != "" {
+		log.Printf("Template %s not found, using default", *templateName)
+	}
+	return ""
+}
+
+// isMultiTest определяет по примерам, что во входе несколько тестов (см. tasks.IsMultiTest).
+// Без примеров считаем, что тестов несколько
+func isMultiTest(tests []Test) bool {
+	for _, test := range tests {
+		if !tasks.IsMultiTest(test.Input, test.Output) {
+			return false
+		}
+	}
+	return true
+}
+
+// extractTaskID берет букву задачи из названия: "A. Title" -> A, "B - Title" -> B
 func extractTaskID(s string) string {
-	parts := strings.SplitN(s, ".", 2)
-	return strings.TrimSpace(parts[0])
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '.' || r == ' ' || r == '-'
+	})
+	if len(parts) == 0 {
+		return ""
+	}
+	return parts[0]
 }
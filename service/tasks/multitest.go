@@ -0,0 +1,94 @@
+package tasks
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// IsMultiTest определяет по примеру, что во входе несколько тестов: вход делится на тесты (см. SplitTestcases),
+// а чтобы не спутать "n и n пар чисел" с t тестами, в ответе должно быть хотя бы t токенов
+func IsMultiTest(input, output string) bool {
+	tests := SplitTestcases(input)
+	return tests != nil && len(strings.Fields(output)) >= len(tests)
+}
+
+// SplitTestcases делит вход мультитеста на тесты: первая строка - одно число t, а остальные строки делятся
+// ровно на t блоков, первые строки которых содержат одинаковое число токенов. Каждый тест возвращается
+// как вход с t = 1, nil - если вход так не делится
+func SplitTestcases(input string) []string {
+	var lines []string
+	for _, line := range strings.Split(input, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, strings.TrimRight(line, " \t\r"))
+		}
+	}
+	if len(lines) < 2 || len(strings.Fields(lines[0])) != 1 {
+		return nil
+	}
+	t, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil || t < 1 {
+		return nil
+	}
+
+	body := lines[1:]
+	sizes := splitBlocks(body, t)
+	if sizes == nil {
+		return nil
+	}
+	tests := make([]string, 0, t)
+	pos := 0
+	for _, size := range sizes {
+		tests = append(tests, "1\n"+strings.Join(body[pos:pos+size], "\n")+"\n")
+		pos += size
+	}
+	return tests
+}
+
+// splitBlocks делит строки ровно на t блоков с одинаковым числом токенов в первой строке и возвращает
+// их длины или nil. Перебор с мемоизацией по (строка, осталось блоков)
+func splitBlocks(lines []string, t int) []int {
+	width := len(strings.Fields(lines[0]))
+	failed := map[[2]int]bool{}
+
+	var sizes []int
+	var split func(pos, left int) bool
+	split = func(pos, left int) bool {
+		if left == 0 {
+			return pos == len(lines)
+		}
+		if len(lines)-pos < left || failed[[2]int{pos, left}] {
+			return false
+		}
+		header := strings.Fields(lines[pos])
+		if len(header) != width {
+			return false
+		}
+		for _, size := range blockSizes(header) {
+			if pos+size <= len(lines) && split(pos+size, left-1) {
+				sizes = append(sizes, size)
+				return true
+			}
+		}
+		failed[[2]int{pos, left}] = true
+		return false
+	}
+	if !split(0, t) {
+		return nil
+	}
+	// размеры добавлялись при выходе из рекурсии - с конца
+	slices.Reverse(sizes)
+	return sizes
+}
+
+// blockSizes - возможные длины блока теста по его первой строке: одна строка ("a b"),
+// строка и массив ("n" + "a1 .. an"), или шапка с n и n строк после нее (+ еще одна строка)
+func blockSizes(header []string) []int {
+	sizes := []int{1, 2}
+	for _, tok := range header {
+		if n, err := strconv.Atoi(tok); err == nil && n > 0 {
+			sizes = append(sizes, 1+n, 2+n)
+		}
+	}
+	return sizes
+}
@@ -0,0 +1,39 @@
+package tasks
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIsMultiTest(t *testing.T) {
+	cases := []struct {
+		name          string
+		input, output string
+		want          bool
+	}{
+		{"single line per test", "3\n1 2\n3 4\n5 6\n", "3\n7\n11\n", true},
+		{"n and array", "2\n3\n1 2 3\n2\n5 6\n", "6\n11\n", true},
+		{"grid header", "2\n2 3\n...\n.#.\n1 1\n#\n", "YES\nNO\n", true},
+		{"n pairs, single answer", "3\n1 2\n3 4\n5 6\n", "21\n", false},
+		{"single test", "5 6\n", "11\n", false},
+		{"count too large", "4\n1\n2\n", "1\n2\n3\n4\n", false},
+		{"not a number", "abc\n1\n", "1\n", false},
+		{"different widths", "2\n1 2\n3\n", "1\n2\n", false},
+	}
+	for _, c := range cases {
+		if got := IsMultiTest(c.input, c.output); got != c.want {
+			t.Errorf("%s: IsMultiTest = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestSplitTestcases(t *testing.T) {
+	got := SplitTestcases("2\n3\n1 2 3\r\n\n2\n5 6\n")
+	want := []string{"1\n3\n1 2 3\n", "1\n2\n5 6\n"}
+	if !slices.Equal(got, want) {
+		t.Errorf("SplitTestcases = %q, want %q", got, want)
+	}
+	if got := SplitTestcases("1 2\n"); got != nil {
+		t.Errorf("SplitTestcases of a single test = %q, want nil", got)
+	}
+}
@@ -0,0 +1,42 @@
+// Package tasks - общее для сервисных утилит (tester, stress, watch, addtest, task_manager):
+// где лежат исходники и тесты задачи и как вход мультитеста делится на отдельные тесты.
+package tasks
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Task - задача: либо буква (файлы в корне, тесты в ./tests), либо директория задачи контеста
+// contests/<platform>/<contest>/<letter> со своими решением и тестами
+type Task struct {
+	Dir    string
+	Letter string
+}
+
+// Resolve по аргументу утилиты возвращает задачу: директория - задача контеста, иначе буква задачи в корне
+func Resolve(arg string) Task {
+	if info, err := os.Stat(arg); err == nil && info.IsDir() {
+		return Task{Dir: filepath.Clean(arg), Letter: filepath.Base(arg)}
+	}
+	return Task{Dir: ".", Letter: arg}
+}
+
+// TestFiles возвращает сохраненные json с тестами задачи: ./tests/<letter>.*.json
+// или все json из <dir>/tests для задачи контеста
+func (t Task) TestFiles() ([]string, error) {
+	if t.Dir == "." {
+		return filepath.Glob("./tests/" + t.Letter + ".*.json")
+	}
+	return filepath.Glob(filepath.Join(t.Dir, "tests", "*.json"))
+}
+
+// CustomDir - директория своих тестов задачи: tests/<letter>/ (или <dir>/tests/<letter>/ для задачи контеста)
+func (t Task) CustomDir() string {
+	return filepath.Join(t.Dir, "tests", t.Letter)
+}
+
+// File возвращает путь до исходника задачи: File("checker") -> <dir>/checker_<letter>.go
+func (t Task) File(kind string) string {
+	return filepath.Join(t.Dir, kind+"_"+t.Letter+".go")
+}
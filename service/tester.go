@@ -2,15 +2,27 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"playground/service/tasks"
 )
 
 var line = "----------------------------------------------------------------------"
@@ -26,33 +38,133 @@ const (
 type Test struct {
 	Input  string `json:"input"`
 	Output string `json:"output"`
+	// Name - имя файла для своих тестов из tests/<letter>/
+	Name string `json:"-"`
+	// NoAnswer - у своего теста нет .ans, решение только запускается и печатается вывод
+	NoAnswer bool `json:"-"`
 }
 
 type Problem struct {
-	Name  string `json:"name"`
-	Group string `json:"group"`
-	Tests []Test `json:"tests"`
+	Name        string `json:"name"`
+	Group       string `json:"group"`
+	TimeLimit   int    `json:"timeLimit"`   // ms
+	MemoryLimit int    `json:"memoryLimit"` // MB
+	Interactive bool   `json:"interactive"`
+	Tests       []Test `json:"tests"`
+}
+
+// Лимиты по умолчанию, если в json задачи их нет
+const (
+	defaultTimeLimit   = 2000 * time.Millisecond
+	defaultMemoryLimit = 256 << 20
+)
+
+type Limits struct {
+	Time   time.Duration
+	Memory int64 // bytes
+}
+
+type Verdict string
+
+const (
+	OK  Verdict = "OK"
+	WA  Verdict = "WA"
+	TLE Verdict = "TLE"
+	RE  Verdict = "RE"
+	MLE Verdict = "MLE"
+	// FAIL - упал сам чекер
+	FAIL Verdict = "FAIL"
+	// RUN - тест без ответа: решение отработало, вывод не проверялся
+	RUN Verdict = "RUN"
+)
+
+type Result struct {
+	Verdict  Verdict
+	Got      string
+	Stderr   string
+	ExitCode int
+	Signal   string
+	Time     time.Duration
+	Memory   int64 // peak RSS, bytes
+	Message  string
+	// Mismatch - первое расхождение с ответом, только для потокенных чекеров
+	Mismatch *Mismatch
+	// Transcript - лог обмена с интерактором, только для интерактивных задач
+	Transcript string
 }
 
+var (
+	tlMultiplier = flag.Float64("tl", 1, "multiplier applied to the problem time limit")
+	checkerName  = flag.String("checker", "", "tokens | float | yesno | path to checker .go file (default: checker_<letter>.go if exists, else tokens)")
+	epsilon      = flag.Float64("eps", 1e-6, "absolute or relative error allowed by the float checker")
+	interactorTL = flag.Duration("interactor-tl", 10*time.Second, "time limit of the interactor")
+	jobs         = flag.Int("j", 0, "number of tests run in parallel (default: number of CPUs), 1 - sequential run with wall-clock timing")
+	reportFormat = flag.String("report", "", "machine-readable report format: json | junit")
+	reportOut    = flag.String("report-out", "-", "report file, - for stdout (the human-readable output then goes to stderr)")
+)
+
 func main() {
-	if len(os.Args) < 3 {
-		fmt.Println("Usage: tester <program_binary> <test_prefix>")
+	flag.Parse()
+	if flag.NArg() < 2 {
+		fmt.Println("Usage: tester [-tl multiplier] [-checker name] [-eps eps] [-interactor-tl d] [-j workers] [-report json|junit] [-report-out file] <program_binary> <task_letter | task_dir>")
 		os.Exit(1)
 	}
 
-	binPath := "./" + os.Args[1]
-	prefix := os.Args[2]
+	binPath := "./" + flag.Arg(0)
+	task := tasks.Resolve(flag.Arg(1))
+
+	// stdout занят отчетом - обычный вывод уходит в stderr
+	reportW := os.Stdout
+	if *reportFormat != "" && *reportOut == "-" {
+		os.Stdout = os.Stderr
+	}
+
+	report := testTask(binPath, task)
+
+	if *reportFormat != "" {
+		if err := writeReport(report, reportW); err != nil {
+			fmt.Printf("Failed to write report: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	// ненулевой код выхода, чтобы make all можно было использовать в скриптах
+	if !report.allPassed() {
+		os.Exit(1)
+	}
+}
 
-	// Ищем все файлы тестов, начинающиеся с prefix
-	files, err := filepath.Glob("./tests/" + prefix + ".*.json")
+func writeReport(report Report, stdout io.Writer) error {
+	if *reportOut == "-" {
+		return report.write(stdout, *reportFormat)
+	}
+	f, err := os.Create(*reportOut)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	if len(files) == 0 {
-		fmt.Printf("No test files found with prefix '%s'\n", prefix)
+	if err := report.write(f, *reportFormat); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// testTask прогоняет решение на всех тестах задачи и возвращает отчет
+func testTask(binPath string, task tasks.Task) Report {
+	meta := readMeta(task)
+
+	checker, cleanup, err := selectChecker(*checkerName, task)
+	if err != nil {
+		fmt.Printf("Failed to prepare checker: %v\n", err)
 		os.Exit(1)
 	}
+	defer cleanup()
 
+	// Ищем все файлы тестов задачи
+	files, err := task.TestFiles()
+	if err != nil {
+		panic(err)
+	}
+	var problems []Problem
 	for _, file := range files {
 		data, err := ioutil.ReadFile(file)
 		if err != nil {
@@ -65,53 +177,1118 @@ func main() {
 			fmt.Printf("Failed to parse JSON in %s: %v\n", file, err)
 			continue
 		}
+		problems = append(problems, prob)
+	}
+
+	// свои тесты из tests/<letter>/*.in идут отдельной группой с лимитами задачи
+	custom, err := customTests(task)
+	if err != nil {
+		fmt.Printf("Failed to read custom tests: %v\n", err)
+	}
+	if len(custom) > 0 {
+		problems = append(problems, customProblem(custom, meta, problems))
+	}
+
+	if len(problems) == 0 {
+		fmt.Printf("No test files found for task '%s'\n", flag.Arg(1))
+		os.Exit(1)
+	}
+
+	report := Report{Task: flag.Arg(1)}
+	for _, prob := range problems {
+		limits := prob.limits()
+		results := make([]Result, 0, len(prob.Tests))
+
+		var interactor *Interactor
+		if prob.Interactive {
+			interactor, err = buildInteractor(task.File("interactor"))
+			if err != nil {
+				fmt.Printf("Failed to prepare interactor: %v\n", err)
+				os.Exit(1)
+			}
+			defer interactor.Cleanup()
+		}
+
+		ready := runParallel(prob.Tests, workers(), func(test Test) Result {
+			if interactor != nil {
+				return interactor.Run(binPath, test, limits)
+			}
+			if test.NoAnswer {
+				return runTest(binPath, test.Input, test.Output, limits, nil)
+			}
+			return runTest(binPath, test.Input, test.Output, limits, checker)
+		})
 
+		labels := make([]string, 0, len(prob.Tests))
 		for i, test := range prob.Tests {
-			fmt.Printf("Test #%d: ", i+1)
-			ok, got, want := runTest(binPath, test.Input, test.Output)
-			if ok {
-				fmt.Println(green + "✅" + reset)
-			} else {
-				fmt.Println("❌")
+			label := fmt.Sprintf("#%d", i+1)
+			if test.Name != "" {
+				label = test.Name
+			}
+			labels = append(labels, label)
+			fmt.Printf("Test %s: ", label)
+
+			res := <-ready[i]
+			results = append(results, res)
+			switch res.Verdict {
+			case OK:
+				fmt.Printf(green+"✅"+reset+" %s\n", res.usage())
+			case RUN:
+				fmt.Printf(yellow+"▶ RUN"+reset+" %s (no answer)\n", res.usage())
+				fmt.Println(bold + "Input:" + reset)
+				fmt.Println(line)
+				fmt.Print(test.Input)
+				fmt.Println(line)
+				fmt.Println(bold + "Output:" + reset)
+				fmt.Println(line)
+				fmt.Print(res.Got)
+				fmt.Println(line)
+			case TLE:
+				fmt.Printf(yellow+"⏰ TLE"+reset+" %s (limit %s)\n", res.usage(), formatDuration(limits.Time))
+			case MLE:
+				fmt.Printf(yellow+"💾 MLE"+reset+" %s (limit %s)\n", res.usage(), formatMemory(limits.Memory))
+			case RE:
+				fmt.Printf(red+"💥 RE"+reset+" %s (%s)\n", res.usage(), res.exitStatus())
 				fmt.Println(bold + "Input:" + reset)
 				fmt.Println(line)
 				fmt.Print(test.Input)
 				fmt.Println(line)
+				fmt.Println(bold + "Stderr:" + reset)
+				fmt.Println(line)
+				fmt.Println(trimTrace(res.Stderr))
+				fmt.Println(line)
+				printTranscript(res)
+			default:
+				fmt.Printf("❌ %s %s\n", res.usage(), res.Message)
+				if res.Mismatch != nil {
+					printDiff(test, res, meta.MultiTest)
+					continue
+				}
+				fmt.Println(bold + "Input:" + reset)
+				fmt.Println(line)
+				fmt.Print(test.Input)
+				fmt.Println(line)
+				if prob.Interactive {
+					if res.Stderr != "" {
+						fmt.Println(bold + "Stderr:" + reset)
+						fmt.Println(line)
+						fmt.Println(trimTrace(res.Stderr))
+						fmt.Println(line)
+					}
+					printTranscript(res)
+					continue
+				}
 				fmt.Println(bold + "Expected output:" + reset)
 				fmt.Println(line)
-				fmt.Println(want)
+				fmt.Println(test.Output)
 				fmt.Println(line)
 				fmt.Println(bold + "Got output:" + reset)
 				fmt.Println(line)
-				fmt.Println(got)
+				fmt.Println(res.Got)
 				fmt.Println(line)
 			}
 		}
+
+		printSummary(labels, results)
+		report.add(prob.Name, prob.Tests, labels, results)
+	}
+	return report
+}
+
+func workers() int {
+	if *jobs > 0 {
+		return *jobs
+	}
+	return runtime.NumCPU()
+}
+
+// runParallel запускает тесты на пуле из workers горутин. Результат i-го теста приходит в i-й канал,
+// так что их можно печатать по порядку, не дожидаясь остальных тестов
+func runParallel(tests []Test, workers int, run func(Test) Result) []chan Result {
+	ready := make([]chan Result, len(tests))
+	for i := range ready {
+		ready[i] = make(chan Result, 1)
+	}
+
+	queue := make(chan int)
+	go func() {
+		for i := range tests {
+			queue <- i
+		}
+		close(queue)
+	}()
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range queue {
+				ready[i] <- run(tests[i])
+			}
+		}()
+	}
+	return ready
+}
+
+// Meta - нужные тестеру поля meta.json, который task_manager сохраняет в директорию задачи
+type Meta struct {
+	TimeLimit   int  `json:"timeLimit"`   // ms
+	MemoryLimit int  `json:"memoryLimit"` // MB
+	Interactive bool `json:"interactive"`
+	MultiTest   bool `json:"multiTest"`
+}
+
+func readMeta(t tasks.Task) Meta {
+	var meta Meta
+	if data, err := os.ReadFile(filepath.Join(t.Dir, "meta.json")); err == nil {
+		if err := json.Unmarshal(data, &meta); err != nil {
+			fmt.Printf("Failed to parse meta.json: %v\n", err)
+		}
+	}
+	return meta
+}
+
+// customTests читает свои тесты: <name>.in - вход, <name>.ans - ответ (необязателен)
+func customTests(t tasks.Task) ([]Test, error) {
+	inputs, err := filepath.Glob(filepath.Join(t.CustomDir(), "*.in"))
+	if err != nil {
+		return nil, err
+	}
+
+	tests := make([]Test, 0, len(inputs))
+	for _, in := range inputs {
+		input, err := os.ReadFile(in)
+		if err != nil {
+			return nil, err
+		}
+		test := Test{Input: string(input), Name: filepath.Base(in)}
+
+		answer, err := os.ReadFile(strings.TrimSuffix(in, ".in") + ".ans")
+		switch {
+		case err == nil:
+			test.Output = string(answer)
+		case os.IsNotExist(err):
+			test.NoAnswer = true
+		default:
+			return nil, err
+		}
+		tests = append(tests, test)
+	}
+	return tests, nil
+}
+
+// customProblem собирает свои тесты в задачу с лимитами из meta.json или из первого json с примерами
+func customProblem(tests []Test, meta Meta, problems []Problem) Problem {
+	prob := Problem{
+		Name:        "custom",
+		TimeLimit:   meta.TimeLimit,
+		MemoryLimit: meta.MemoryLimit,
+		Interactive: meta.Interactive,
+		Tests:       tests,
+	}
+	if len(problems) > 0 {
+		if prob.TimeLimit == 0 {
+			prob.TimeLimit = problems[0].TimeLimit
+		}
+		if prob.MemoryLimit == 0 {
+			prob.MemoryLimit = problems[0].MemoryLimit
+		}
+		prob.Interactive = prob.Interactive || problems[0].Interactive
+	}
+	return prob
+}
+
+// limits возвращает лимиты на тест, лимит времени - с учетом множителя -tl
+func (p Problem) limits() Limits {
+	timeLimit := defaultTimeLimit
+	if p.TimeLimit > 0 {
+		timeLimit = time.Duration(p.TimeLimit) * time.Millisecond
+	}
+	memoryLimit := int64(defaultMemoryLimit)
+	if p.MemoryLimit > 0 {
+		memoryLimit = int64(p.MemoryLimit) << 20
+	}
+	return Limits{
+		Time:   time.Duration(float64(timeLimit) * *tlMultiplier),
+		Memory: memoryLimit,
+	}
+}
+
+func printSummary(labels []string, results []Result) {
+	width := 6
+	for _, label := range labels {
+		width = max(width, len(label))
+	}
+
+	fmt.Println(line)
+	fmt.Printf(bold+"%-*s %-8s %8s %10s"+reset+"\n", width, "Test", "Verdict", "Time", "Memory")
+	for i, res := range results {
+		verdict := fmt.Sprintf("%-8s", res.Verdict)
+		switch res.Verdict {
+		case OK:
+			verdict = green + verdict + reset
+		case RUN:
+			verdict = yellow + verdict + reset
+		default:
+			verdict = red + verdict + reset
+		}
+		fmt.Printf("%-*s %s %8s %10s\n", width, labels[i], verdict, formatDuration(res.Time), formatMemory(res.Memory))
 	}
+	fmt.Println(line)
 }
 
-func runTest(binPath, input, expectedOutput string) (bool, string, string) {
-	cmd := exec.Command(binPath)
+// runTest запускает решение на тесте, checker == nil - тест без ответа.
+// При параллельном запуске соседние тесты замедляют друг друга, поэтому время - процессорное (user + sys),
+// а процесс убивается по wall clock только с двойным запасом. При -j=1 время и TLE - по wall clock
+func runTest(binPath, input, expectedOutput string, limits Limits, checker Checker) Result {
+	timeout := limits.Time
+	parallel := workers() > 1
+	if parallel {
+		timeout *= 2
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, binPath)
 	cmd.Stdin = strings.NewReader(input)
 
-	var stdout bytes.Buffer
+	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
 
+	start := time.Now()
 	err := cmd.Run()
+	elapsed := time.Since(start)
+
+	res := Result{Got: stdout.String(), Stderr: stderr.String(), Time: elapsed}
+	if cmd.ProcessState != nil {
+		res.Memory = peakMemory(cmd.ProcessState)
+		if parallel {
+			res.Time = cmd.ProcessState.UserTime() + cmd.ProcessState.SystemTime()
+		}
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) || res.Time > limits.Time {
+		res.Verdict = TLE
+		return res
+	}
+	if res.Memory > limits.Memory {
+		res.Verdict = MLE
+		return res
+	}
 	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			// бинарник не удалось запустить - дальше тестировать нечего
+			log.Fatal(err)
+		}
+		res.Verdict = RE
+		res.ExitCode = exitErr.ExitCode()
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			res.Signal = status.Signal().String()
+		}
+		return res
+	}
+
+	if checker == nil {
+		res.Verdict = RUN
+		return res
+	}
+	res.Verdict, res.Message = checker.Check(input, expectedOutput, res.Got)
+	if tc, ok := checker.(tokenComparer); ok && res.Verdict == WA {
+		res.Mismatch = firstMismatch(expectedOutput, res.Got, tc.equal)
+	}
+	return res
+}
+
+// exitStatus описывает причину падения: код выхода или сигнал
+func (r Result) exitStatus() string {
+	if r.Signal != "" {
+		return "signal: " + r.Signal
+	}
+	return fmt.Sprintf("exit code %d", r.ExitCode)
+}
+
+// trimTrace оставляет от трейса паники сообщение и фреймы решения (package main),
+// выкидывая фреймы рантайма. Если stderr не похож на панику, возвращает его как есть.
+func trimTrace(stderr string) string {
+	lines := strings.Split(strings.TrimRight(stderr, "\n"), "\n")
+
+	goroutineIdx := -1
+	for i, l := range lines {
+		if strings.HasPrefix(l, "goroutine ") {
+			goroutineIdx = i
+			break
+		}
+	}
+	if goroutineIdx == -1 {
+		return strings.Join(lines, "\n")
+	}
+
+	res := append([]string{}, lines[:goroutineIdx+1]...)
+	// фреймы идут парами: функция, затем "\tфайл:строка +0x.."
+	for i := goroutineIdx + 1; i+1 < len(lines); i += 2 {
+		if strings.HasPrefix(lines[i], "main.") {
+			res = append(res, lines[i], lines[i+1])
+		}
+	}
+	return strings.Join(res, "\n")
+}
+
+// peakMemory возвращает пиковое потребление памяти процессом (maxrss) в байтах
+func peakMemory(state *os.ProcessState) int64 {
+	rusage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return 0
+	}
+	// на linux maxrss в килобайтах, на darwin - в байтах
+	if runtime.GOOS == "darwin" {
+		return rusage.Maxrss
+	}
+	return rusage.Maxrss << 10
+}
+
+func (r Result) usage() string {
+	return formatDuration(r.Time) + " " + formatMemory(r.Memory)
+}
+
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
+
+func formatMemory(bytes int64) string {
+	return fmt.Sprintf("%.1fMB", float64(bytes)/(1<<20))
+}
+
+// Checker сравнивает вывод решения с ожидаемым и возвращает вердикт и пояснение
+type Checker interface {
+	Check(input, expected, actual string) (Verdict, string)
+}
+
+// selectChecker выбирает чекер по имени из флага -checker.
+// Без флага используется checker_<letter>.go, если он есть, иначе потокенное сравнение.
+// cleanup удаляет временные файлы чекера.
+func selectChecker(name string, task tasks.Task) (Checker, func(), error) {
+	noop := func() {}
+
+	if name == "" {
+		name = "tokens"
+		if src := task.File("checker"); fileExists(src) {
+			name = src
+		}
+	}
+
+	switch name {
+	case "tokens":
+		return tokensChecker{}, noop, nil
+	case "float":
+		return floatChecker{eps: *epsilon}, noop, nil
+	case "yesno":
+		return yesNoChecker{}, noop, nil
+	default:
+		return buildExternalChecker(name)
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// tokenComparer - чекер, сравнивающий вывод потокенно. Для таких чекеров тестер показывает
+// первое расхождение в выводе
+type tokenComparer interface {
+	Checker
+	equal(want, got string) bool
+}
+
+// tokensChecker - точное сравнение токенов, пробельные символы не важны
+type tokensChecker struct{}
+
+func (c tokensChecker) Check(_, expected, actual string) (Verdict, string) {
+	return compareTokens(expected, actual, c.equal)
+}
+
+func (tokensChecker) equal(want, got string) bool {
+	return want == got
+}
+
+// floatChecker - токены-числа сравниваются с абсолютной или относительной погрешностью eps,
+// остальные токены - точно
+type floatChecker struct {
+	eps float64
+}
+
+func (c floatChecker) Check(_, expected, actual string) (Verdict, string) {
+	return compareTokens(expected, actual, c.equal)
+}
+
+func (c floatChecker) equal(want, got string) bool {
+	if want == got {
+		return true
+	}
+	w, errW := strconv.ParseFloat(want, 64)
+	g, errG := strconv.ParseFloat(got, 64)
+	if errW != nil || errG != nil {
+		return false
+	}
+	return math.Abs(w-g) <= c.eps*math.Max(1, math.Abs(w))
+}
+
+// yesNoChecker - сравнение токенов без учета регистра (YES / yes / Yes)
+type yesNoChecker struct{}
+
+func (c yesNoChecker) Check(_, expected, actual string) (Verdict, string) {
+	return compareTokens(expected, actual, c.equal)
+}
+
+func (yesNoChecker) equal(want, got string) bool {
+	return strings.EqualFold(want, got)
+}
+
+func compareTokens(expected, actual string, equal func(want, got string) bool) (Verdict, string) {
+	if m := firstMismatch(expected, actual, equal); m != nil {
+		return WA, m.String()
+	}
+	return OK, ""
+}
+
+// Token - токен вывода и его позиция, строки и колонки с 1
+type Token struct {
+	Text string
+	Line int
+	Col  int
+}
+
+func tokenize(s string) []Token {
+	var tokens []Token
+	for i, l := range strings.Split(s, "\n") {
+		for col := 0; col < len(l); {
+			if isSpace(l[col]) {
+				col++
+				continue
+			}
+			end := col
+			for end < len(l) && !isSpace(l[end]) {
+				end++
+			}
+			tokens = append(tokens, Token{Text: l[col:end], Line: i + 1, Col: col + 1})
+			col = end
+		}
+	}
+	return tokens
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\v' || c == '\f'
+}
+
+// Mismatch - первый несовпавший токен. Want или Got равен nil, если соответствующий вывод закончился раньше
+type Mismatch struct {
+	Want *Token
+	Got  *Token
+}
+
+func firstMismatch(expected, actual string, equal func(want, got string) bool) *Mismatch {
+	want, got := tokenize(expected), tokenize(actual)
+	for i := 0; i < len(want) || i < len(got); i++ {
+		var m Mismatch
+		if i < len(want) {
+			m.Want = &want[i]
+		}
+		if i < len(got) {
+			m.Got = &got[i]
+		}
+		if m.Want == nil || m.Got == nil || !equal(m.Want.Text, m.Got.Text) {
+			return &m
+		}
+	}
+	return nil
+}
+
+// String - позиция в выводе решения и что ожидалось, например `line 3, col 5: expected "7", got "8"`
+func (m Mismatch) String() string {
+	switch {
+	case m.Got == nil:
+		return fmt.Sprintf("expected %q at line %d, col %d, got end of output", m.Want.Text, m.Want.Line, m.Want.Col)
+	case m.Want == nil:
+		return fmt.Sprintf("line %d, col %d: expected end of output, got %q", m.Got.Line, m.Got.Col, m.Got.Text)
+	}
+	msg := fmt.Sprintf("line %d, col %d: expected %q, got %q", m.Got.Line, m.Got.Col, m.Want.Text, m.Got.Text)
+	if m.Want.Line != m.Got.Line || m.Want.Col != m.Got.Col {
+		msg += fmt.Sprintf(" (expected at line %d, col %d)", m.Want.Line, m.Want.Col)
+	}
+	return msg
+}
+
+// externalChecker - пользовательский чекер в стиле testlib.
+// Запускается как `checker <input> <expected> <actual>`, код выхода 0 - OK, 1 - WA, 2 - PE (тоже WA),
+// 3 и любой другой - ошибка чекера. Вывод чекера (stdout + stderr) используется как пояснение.
+type externalChecker struct {
+	binPath string
+	dir     string
+}
+
+func buildExternalChecker(src string) (Checker, func(), error) {
+	dir, err := os.MkdirTemp("", "checker")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+
+	binPath := filepath.Join(dir, "checker")
+	if err := buildGo(src, binPath); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	fmt.Printf(bold+"Using checker %s"+reset+"\n", src)
+	return externalChecker{binPath: binPath, dir: dir}, cleanup, nil
+}
+
+func (c externalChecker) Check(input, expected, actual string) (Verdict, string) {
+	args, cleanup, err := writeTempFiles(c.dir, input, expected, actual)
+	if err != nil {
+		return FAIL, err.Error()
+	}
+	defer cleanup()
+
+	out, err := exec.Command(c.binPath, args...).CombinedOutput()
+	msg := strings.TrimSpace(string(out))
+	if err == nil {
+		return OK, msg
+	}
+
+	if wa, note := wrongAnswer(err); wa {
+		return WA, strings.TrimSpace(note + " " + msg)
+	}
+	return FAIL, strings.TrimSpace(err.Error() + " " + msg)
+}
+
+// Коды выхода testlib: 1 - _wa, 2 - _pe (неверный формат вывода, засчитываем как WA),
+// 3 - _fail и любые другие коды - ошибка самого чекера или интерактора
+const (
+	exitWA = 1
+	exitPE = 2
+)
+
+// wrongAnswer проверяет, что чекер или интерактор завершился с WA или PE, для PE возвращает пометку к пояснению
+func wrongAnswer(err error) (bool, string) {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false, ""
+	}
+	switch exitErr.ExitCode() {
+	case exitWA:
+		return true, ""
+	case exitPE:
+		return true, "presentation error:"
+	}
+	return false, ""
+}
+
+// writeTempFiles записывает содержимое во временные файлы 0.txt, 1.txt, ... в отдельной поддиректории dir,
+// чтобы параллельные тесты не перезаписывали файлы друг друга
+func writeTempFiles(dir string, contents ...string) ([]string, func(), error) {
+	sub, err := os.MkdirTemp(dir, "run")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { _ = os.RemoveAll(sub) }
+
+	paths := make([]string, 0, len(contents))
+	for i, content := range contents {
+		path := filepath.Join(sub, fmt.Sprintf("%d.txt", i))
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, cleanup, nil
+}
+
+func buildGo(src, binPath string) error {
+	build := exec.Command("go", "build", "-o", binPath, src)
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		return fmt.Errorf("build %s: %w", src, err)
+	}
+	return nil
+}
+
+// Interactor - пользовательский интерактор для интерактивных задач.
+// Запускается как `interactor <input> <expected>`, его stdout подключен к stdin решения и наоборот.
+// Код выхода 0 - OK, 1 - WA, 2 - PE (тоже WA), любой другой - ошибка интерактора. Stderr интерактора - пояснение к вердикту.
+type Interactor struct {
+	binPath string
+	dir     string
+}
+
+func buildInteractor(src string) (*Interactor, error) {
+	if !fileExists(src) {
+		return nil, fmt.Errorf("problem is interactive, but %s not found", src)
+	}
+	dir, err := os.MkdirTemp("", "interactor")
+	if err != nil {
+		return nil, err
+	}
+	binPath := filepath.Join(dir, "interactor")
+	if err := buildGo(src, binPath); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+
+	fmt.Printf(bold+"Using interactor %s"+reset+"\n", src)
+	return &Interactor{binPath: binPath, dir: dir}, nil
+}
+
+func (it *Interactor) Cleanup() {
+	_ = os.RemoveAll(it.dir)
+}
+
+// Run запускает решение в паре с интерактором. Лимит времени решения берется из задачи,
+// интерактора - из флага -interactor-tl.
+func (it *Interactor) Run(binPath string, test Test, limits Limits) Result {
+	args, cleanup, err := writeTempFiles(it.dir, test.Input, test.Output)
+	if err != nil {
+		return Result{Verdict: FAIL, Message: err.Error()}
+	}
+	defer cleanup()
+
+	solCtx, solCancel := context.WithTimeout(context.Background(), limits.Time)
+	defer solCancel()
+	interCtx, interCancel := context.WithTimeout(context.Background(), *interactorTL)
+	defer interCancel()
+
+	sol := exec.CommandContext(solCtx, binPath)
+	inter := exec.CommandContext(interCtx, it.binPath, args...)
+
+	solStdin, err := sol.StdinPipe()
+	if err != nil {
+		return Result{Verdict: FAIL, Message: err.Error()}
+	}
+	interStdin, err := inter.StdinPipe()
+	if err != nil {
+		return Result{Verdict: FAIL, Message: err.Error()}
+	}
+
+	tr := &transcript{}
+	var solStderr, interStderr bytes.Buffer
+	sol.Stdout = io.MultiWriter(tr.side("> "), ignoreErrors{interStdin})
+	sol.Stderr = &solStderr
+	inter.Stdout = io.MultiWriter(tr.side("< "), ignoreErrors{solStdin})
+	inter.Stderr = &interStderr
+
+	start := time.Now()
+	if err := inter.Start(); err != nil {
+		return Result{Verdict: FAIL, Message: err.Error()}
+	}
+	if err := sol.Start(); err != nil {
 		log.Fatal(err)
-		return false, "", expectedOutput
 	}
 
-	got := stdout.String()
-	want := expectedOutput
+	var (
+		solErr  error
+		elapsed time.Duration
+		solDone = make(chan struct{})
+	)
+	go func() {
+		solErr = sol.Wait()
+		elapsed = time.Since(start)
+		// интерактор должен увидеть EOF, если решение завершилось
+		_ = interStdin.Close()
+		close(solDone)
+	}()
+	interErr := inter.Wait()
+	_ = solStdin.Close()
+	<-solDone
 
-	return normalize(got) == normalize(want), got, want
+	res := Result{
+		Stderr:     solStderr.String(),
+		Time:       elapsed,
+		Message:    strings.TrimSpace(interStderr.String()),
+		Transcript: tr.String(),
+	}
+	if sol.ProcessState != nil {
+		res.Memory = peakMemory(sol.ProcessState)
+	}
+
+	var exitErr *exec.ExitError
+	interWA, note := wrongAnswer(interErr)
+	switch {
+	case errors.Is(solCtx.Err(), context.DeadlineExceeded):
+		res.Verdict = TLE
+	case res.Memory > limits.Memory:
+		res.Verdict = MLE
+	case errors.Is(interCtx.Err(), context.DeadlineExceeded):
+		res.Verdict = FAIL
+		res.Message = strings.TrimSpace(fmt.Sprintf("interactor timeout %s %s", *interactorTL, res.Message))
+	case interErr != nil && !interWA:
+		res.Verdict = FAIL
+		res.Message = strings.TrimSpace(interErr.Error() + " " + res.Message)
+	case interErr != nil:
+		res.Verdict = WA
+		res.Message = strings.TrimSpace(note + " " + res.Message)
+		if errors.As(solErr, &exitErr) {
+			res.Message = strings.TrimSpace(fmt.Sprintf("%s (solution exit code %d)", res.Message, exitErr.ExitCode()))
+		}
+	case errors.As(solErr, &exitErr):
+		res.Verdict = RE
+		res.ExitCode = exitErr.ExitCode()
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			res.Signal = status.Signal().String()
+		}
+	default:
+		res.Verdict = OK
+	}
+	return res
 }
 
-var spaceCollapse = regexp.MustCompile(`\s+`)
+// transcript собирает обмен решения с интерактором: "> " - вывод решения, "< " - вывод интерактора
+type transcript struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+type transcriptSide struct {
+	t      *transcript
+	prefix string
+}
+
+func (t *transcript) side(prefix string) io.Writer {
+	return transcriptSide{t: t, prefix: prefix}
+}
+
+func (w transcriptSide) Write(p []byte) (int, error) {
+	w.t.mu.Lock()
+	defer w.t.mu.Unlock()
+	for _, l := range strings.SplitAfter(string(p), "\n") {
+		if l == "" {
+			continue
+		}
+		w.t.buf.WriteString(w.prefix + l)
+		if !strings.HasSuffix(l, "\n") {
+			w.t.buf.WriteString("\n")
+		}
+	}
+	return len(p), nil
+}
+
+func (t *transcript) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.buf.String()
+}
+
+// ignoreErrors не дает упасть копированию вывода, если вторая сторона уже завершилась
+type ignoreErrors struct {
+	w io.Writer
+}
+
+func (w ignoreErrors) Write(p []byte) (int, error) {
+	_, _ = w.w.Write(p)
+	return len(p), nil
+}
+
+func printTranscript(res Result) {
+	if res.Transcript == "" {
+		return
+	}
+	fmt.Println(bold + "Transcript (> solution, < interactor):" + reset)
+	fmt.Println(line)
+	fmt.Print(res.Transcript)
+	fmt.Println(line)
+}
+
+// diffContext - сколько строк вокруг расхождения показывать
+const diffContext = 3
+
+// printDiff печатает ожидаемый и полученный вывод вокруг первого расхождения с подсвеченным токеном.
+// Для мультитеста, если удалось понять, в каком тесте расхождение, печатается только этот тест:
+// его вход (в виде отдельного теста с t = 1) и его строки вывода
+func printDiff(test Test, res Result, multiTest bool) {
+	m := res.Mismatch
+	want := strings.Split(strings.TrimRight(test.Output, "\n"), "\n")
+	got := strings.Split(strings.TrimRight(res.Got, "\n"), "\n")
+
+	// строки расхождения; если вывод закончился, расхождение - сразу после последней строки
+	wantLine, gotLine := len(want)+1, len(got)+1
+	if m.Want != nil {
+		wantLine = m.Want.Line
+	}
+	if m.Got != nil {
+		gotLine = m.Got.Line
+	}
+	from, to := wantLine-diffContext, wantLine+diffContext
+
+	input := test.Input
+	if tc, ok := findTestcase(test, wantLine, multiTest); ok {
+		fmt.Printf(bold+"Testcase #%d of %d"+reset+"\n", tc.Index, tc.Count)
+		input = tc.Input
+		from, to = tc.From, tc.To
+	}
+	shift := gotLine - wantLine
+
+	fmt.Println(bold + "Input:" + reset)
+	fmt.Println(line)
+	fmt.Print(input)
+	fmt.Println(line)
+	fmt.Println(bold + "Expected output:" + reset)
+	fmt.Println(line)
+	printLines(want, from, to, m.Want)
+	fmt.Println(line)
+	fmt.Println(bold + "Got output:" + reset)
+	fmt.Println(line)
+	printLines(got, from+shift, to+shift, m.Got)
+	fmt.Println(line)
+}
+
+// printLines печатает строки [from, to] с номерами, подсвечивая токен tok.
+// Если tok == nil, расхождение в конце вывода - печатается маркер конца
+func printLines(lines []string, from, to int, tok *Token) {
+	from, to = max(from, 1), min(to, len(lines))
+	if from > 1 {
+		fmt.Println("   ...")
+	}
+	for i := from; i <= to; i++ {
+		l := lines[i-1]
+		if tok != nil && tok.Line == i {
+			start, end := tok.Col-1, tok.Col-1+len(tok.Text)
+			l = l[:start] + red + bold + l[start:end] + reset + l[end:]
+		}
+		fmt.Printf("%4d | %s\n", i, l)
+	}
+	if tok == nil {
+		fmt.Println(red + "   <end of output>" + reset)
+	} else if to < len(lines) {
+		fmt.Println("   ...")
+	}
+}
+
+// Testcase - один тест из мультитеста: его номер, вход и строки ожидаемого вывода [From, To]
+type Testcase struct {
+	Index int
+	Count int
+	Input string
+	From  int
+	To    int
+}
+
+// findTestcase определяет тест мультитеста, к которому относится строка ожидаемого вывода.
+// Вход делится на t тестов через tasks.SplitTestcases, как и при определении multiTest в task_manager, а вывод - поровну
+// по строкам, поэтому тест находится, только если каждый тест выводит одинаковое число строк
+func findTestcase(test Test, wantLine int, multiTest bool) (Testcase, bool) {
+	if !multiTest {
+		return Testcase{}, false
+	}
+	inputs := tasks.SplitTestcases(test.Input)
+	if inputs == nil {
+		return Testcase{}, false
+	}
+	t := len(inputs)
+	output := strings.Split(strings.TrimRight(test.Output, "\n"), "\n")
+	if len(output)%t != 0 {
+		return Testcase{}, false
+	}
+	per := len(output) / t
+	k := min((wantLine-1)/per, t-1)
+	return Testcase{
+		Index: k + 1,
+		Count: t,
+		Input: inputs[k],
+		From:  k*per + 1,
+		To:    (k + 1) * per,
+	}, true
+}
+
+// Report - машиночитаемый отчет о прогоне для редакторов и скриптов (-report json|junit)
+type Report struct {
+	Task   string        `json:"task"`
+	Passed bool          `json:"passed"`
+	Groups []ReportGroup `json:"groups"`
+}
+
+// ReportGroup - тесты одного json задачи или свои тесты (custom)
+type ReportGroup struct {
+	Name  string       `json:"name"`
+	Tests []ReportTest `json:"tests"`
+}
+
+type ReportTest struct {
+	Name     string          `json:"name"`
+	Verdict  Verdict         `json:"verdict"`
+	TimeMs   int64           `json:"timeMs"`
+	Memory   int64           `json:"memoryBytes"`
+	Message  string          `json:"message,omitempty"`
+	Mismatch *ReportMismatch `json:"mismatch,omitempty"`
+	Stderr   string          `json:"stderr,omitempty"`
+	Input    string          `json:"input"`
+	Expected string          `json:"expected,omitempty"`
+	Got      string          `json:"got"`
+}
+
+// ReportMismatch - позиция первого расхождения в выводе решения, Got пустой, если вывод закончился раньше
+type ReportMismatch struct {
+	Line     int    `json:"line"`
+	Col      int    `json:"col"`
+	Expected string `json:"expected"`
+	Got      string `json:"got"`
+}
+
+func (r *Report) add(name string, tests []Test, labels []string, results []Result) {
+	group := ReportGroup{Name: name}
+	for i, res := range results {
+		t := ReportTest{
+			Name:     labels[i],
+			Verdict:  res.Verdict,
+			TimeMs:   res.Time.Milliseconds(),
+			Memory:   res.Memory,
+			Message:  res.Message,
+			Stderr:   res.Stderr,
+			Input:    tests[i].Input,
+			Expected: tests[i].Output,
+			Got:      res.Got,
+		}
+		if res.Verdict == RE && t.Message == "" {
+			t.Message = res.exitStatus()
+		}
+		if m := res.Mismatch; m != nil {
+			t.Mismatch = &ReportMismatch{}
+			if m.Want != nil {
+				t.Mismatch.Expected = m.Want.Text
+				t.Mismatch.Line, t.Mismatch.Col = m.Want.Line, m.Want.Col
+			}
+			if m.Got != nil {
+				t.Mismatch.Got = m.Got.Text
+				t.Mismatch.Line, t.Mismatch.Col = m.Got.Line, m.Got.Col
+			}
+		}
+		group.Tests = append(group.Tests, t)
+	}
+	r.Groups = append(r.Groups, group)
+}
+
+// allPassed - все тесты прошли, тесты без ответа (RUN) не считаются упавшими
+func (r Report) allPassed() bool {
+	for _, g := range r.Groups {
+		for _, t := range g.Tests {
+			if t.Verdict != OK && t.Verdict != RUN {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r Report) write(w io.Writer, format string) error {
+	r.Passed = r.allPassed()
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "    ")
+		return enc.Encode(r)
+	case "junit":
+		return r.writeJUnit(w)
+	default:
+		return fmt.Errorf("unknown report format %q, expected json or junit", format)
+	}
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	Skipped   *junitProblem `xml:"skipped,omitempty"`
+	SystemOut *junitText    `xml:"system-out,omitempty"`
+	SystemErr *junitText    `xml:"system-err,omitempty"`
+}
+
+type junitProblem struct {
+	Type    string `xml:"type,attr,omitempty"`
+	Message string `xml:"message,attr,omitempty"`
+	Text    string `xml:",cdata"`
+}
+
+// junitText - вывод теста, в CDATA, чтобы переводы строк не экранировались
+type junitText struct {
+	Text string `xml:",cdata"`
+}
+
+func cdata(s string) *junitText {
+	if s == "" {
+		return nil
+	}
+	return &junitText{Text: s}
+}
+
+// writeJUnit пишет отчет в формате JUnit XML: WA - failure, TLE/MLE/RE/FAIL - error, RUN - skipped
+func (r Report) writeJUnit(w io.Writer) error {
+	suites := junitSuites{Name: r.Task}
+	for _, g := range r.Groups {
+		suite := junitSuite{Name: g.Name, Tests: len(g.Tests)}
+		var total int64
+		for _, t := range g.Tests {
+			total += t.TimeMs
+			c := junitCase{
+				Name:      t.Name,
+				ClassName: g.Name,
+				Time:      seconds(t.TimeMs),
+				SystemOut: cdata(t.Got),
+				SystemErr: cdata(t.Stderr),
+			}
+			problem := &junitProblem{Type: string(t.Verdict), Message: t.Message}
+			switch t.Verdict {
+			case OK:
+			case RUN:
+				c.Skipped = &junitProblem{Message: "no answer"}
+			case WA:
+				problem.Text = "Input:\n" + t.Input + "\nExpected:\n" + t.Expected
+				c.Failure = problem
+				suite.Failures++
+			default:
+				problem.Text = "Input:\n" + t.Input
+				c.Error = problem
+				suite.Errors++
+			}
+			suite.Cases = append(suite.Cases, c)
+		}
+		suite.Time = seconds(total)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
 
-// normalize - Заменяет все \n, \t, множественные пробелы и т.д. на один пробел
-func normalize(s string) string {
-	return strings.TrimSpace(spaceCollapse.ReplaceAllString(s, " "))
+func seconds(ms int64) string {
+	return strconv.FormatFloat(float64(ms)/1000, 'f', 3, 64)
 }
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"playground/service/tasks"
+)
+
+const (
+	reset       = "\033[0m"
+	red         = "\033[31m"
+	green       = "\033[32m"
+	yellow      = "\033[33m"
+	bold        = "\033[1m"
+	clearScreen = "\033[H\033[2J"
+)
+
+var (
+	interval = flag.Duration("interval", 300*time.Millisecond, "how often files are checked for changes")
+	debounce = flag.Duration("debounce", 200*time.Millisecond, "quiet period after the last change before rerun")
+)
+
+// Исходники других задач в корне - их изменения не влияют на текущую задачу
+var taskFile = regexp.MustCompile(`^(task|brute|gen|checker|interactor)_.+\.go$`)
+
+// Режим наблюдения: при сохранении решения, библиотечных файлов, чекера/интерактора или тестов
+// заново запускаются merge, build и тесты. Аргументы после задачи передаются тестеру как есть.
+func main() {
+	flag.Parse()
+	if flag.NArg() < 1 {
+		fmt.Println("Usage: watch [-interval d] [-debounce d] <task_letter | task_dir> [tester flags...]")
+		os.Exit(1)
+	}
+	arg := flag.Arg(0)
+	task := tasks.Resolve(arg)
+	testerArgs := flag.Args()[1:]
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// merger и tester собираются один раз, чтобы не ждать go run на каждое сохранение
+	tools, err := os.MkdirTemp("", "watch")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(tools)
+	merger, tester := filepath.Join(tools, "merger"), filepath.Join(tools, "tester")
+	for bin, src := range map[string]string{merger: "./service/merger.go", tester: "./service/tester.go"} {
+		if err := goBuild(ctx, bin, src); err != nil {
+			fmt.Printf(red+"%v"+reset+"\n", err)
+			os.Exit(1)
+		}
+	}
+
+	pipeline := func(changed []string) {
+		fmt.Print(clearScreen)
+		fmt.Printf(bold+"[%s] %s"+reset+"\n", time.Now().Format("15:04:05"), strings.Join(changed, ", "))
+
+		binary := filepath.Join(task.Dir, "task_"+task.Letter)
+		defer os.Remove(binary)
+
+		steps := []*exec.Cmd{
+			exec.CommandContext(ctx, merger, arg),
+			exec.CommandContext(ctx, "go", "build", "-o", binary, filepath.Join(task.Dir, "solutions", "task_"+task.Letter+".go")),
+			exec.CommandContext(ctx, tester, append(testerArgs, binary, arg)...),
+		}
+		for i, cmd := range steps {
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			if err := cmd.Run(); err != nil {
+				// упавшие тесты тестер уже показал сам
+				if i < len(steps)-1 {
+					fmt.Printf(red+"%s: %v"+reset+"\n", filepath.Base(cmd.Path), err)
+				}
+				break
+			}
+		}
+		fmt.Printf(yellow+"Watching %s, Ctrl+C to stop"+reset+"\n", task.File("task"))
+	}
+
+	files := snapshot(task)
+	pipeline([]string{"start"})
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Println()
+			return
+		case <-ticker.C:
+		}
+
+		changed := diff(files, snapshot(task))
+		if len(changed) == 0 {
+			continue
+		}
+
+		// дожидаемся, пока файлы перестанут меняться: редакторы часто пишут файл в несколько приемов
+		for {
+			files = snapshot(task)
+			select {
+			case <-ctx.Done():
+				fmt.Println()
+				return
+			case <-time.After(*debounce):
+			}
+			more := diff(files, snapshot(task))
+			if len(more) == 0 {
+				break
+			}
+			changed = append(changed, more...)
+		}
+		pipeline(unique(changed))
+	}
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// snapshot собирает время изменения и размер наблюдаемых файлов: библиотечные файлы в корне,
+// исходники задачи (решение, чекер, интерактор) и ее тесты
+func snapshot(task tasks.Task) map[string]fileState {
+	files := make(map[string]fileState)
+	add := func(path string) {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			files[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+
+	libs, _ := filepath.Glob("./*.go")
+	for _, path := range libs {
+		if !taskFile.MatchString(filepath.Base(path)) && !strings.HasSuffix(path, "_test.go") {
+			add(path)
+		}
+	}
+	for _, kind := range []string{"task", "checker", "interactor"} {
+		add(task.File(kind))
+	}
+
+	tests, _ := task.TestFiles()
+	for _, path := range tests {
+		add(path)
+	}
+	_ = filepath.WalkDir(task.CustomDir(), func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			add(path)
+		}
+		return nil
+	})
+	return files
+}
+
+// diff возвращает измененные, новые и удаленные файлы
+func diff(before, after map[string]fileState) []string {
+	var changed []string
+	for path, state := range after {
+		if old, ok := before[path]; !ok || old != state {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
+
+func unique(paths []string) []string {
+	seen := make(map[string]bool)
+	var res []string
+	for _, path := range paths {
+		if !seen[path] {
+			seen[path] = true
+			res = append(res, path)
+		}
+	}
+	return res
+}
+
+func goBuild(ctx context.Context, bin, src string) error {
+	build := exec.CommandContext(ctx, "go", "build", "-o", bin, src)
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		return fmt.Errorf("build %s: %w", src, err)
+	}
+	return nil
+}
@@ -75,6 +75,131 @@ func (set *OrderedSet[T]) Values() []T {
 	return set.tree.Keys()
 }
 
+// At returns the i-th smallest element (0-based) in O(log n), like find_by_order in c++.
+// Panics if i is out of range.
+func (set *OrderedSet[T]) At(i int) T {
+	key, found := set.tree.KthKey(i)
+	if !found {
+		panic(fmt.Sprintf("OrderedSet: index %d out of range [0, %d)", i, set.tree.Size()))
+	}
+	return key
+}
+
+// IndexOf returns the index of the item in sorted order or -1 if the item is not in the set, O(log n).
+func (set *OrderedSet[T]) IndexOf(item T) int {
+	if !set.Contains(item) {
+		return -1
+	}
+	return set.tree.Rank(item)
+}
+
+// CountLess returns the number of elements strictly less than the item in O(log n), like order_of_key in c++.
+func (set *OrderedSet[T]) CountLess(item T) int {
+	return set.tree.Rank(item)
+}
+
+// LowerBound returns the smallest element greater than or equal to the item, like lower_bound in c++.
+// Second return parameter is false if there is no such element.
+func (set *OrderedSet[T]) LowerBound(item T) (T, bool) {
+	return keyOf(set.tree.Ceiling(item))
+}
+
+// UpperBound returns the smallest element strictly greater than the item, like upper_bound in c++.
+// Second return parameter is false if there is no such element.
+func (set *OrderedSet[T]) UpperBound(item T) (T, bool) {
+	return keyOf(set.tree.Higher(item))
+}
+
+// Ceiling returns the smallest element greater than or equal to the item, same as LowerBound.
+func (set *OrderedSet[T]) Ceiling(item T) (T, bool) {
+	return keyOf(set.tree.Ceiling(item))
+}
+
+// Floor returns the largest element smaller than or equal to the item.
+func (set *OrderedSet[T]) Floor(item T) (T, bool) {
+	return keyOf(set.tree.Floor(item))
+}
+
+// Higher returns the smallest element strictly greater than the item, same as UpperBound.
+func (set *OrderedSet[T]) Higher(item T) (T, bool) {
+	return keyOf(set.tree.Higher(item))
+}
+
+// Lower returns the largest element strictly smaller than the item.
+func (set *OrderedSet[T]) Lower(item T) (T, bool) {
+	return keyOf(set.tree.Lower(item))
+}
+
+// Min returns the smallest element, second return parameter is false if the set is empty.
+func (set *OrderedSet[T]) Min() (T, bool) {
+	node := set.tree.Left()
+	return keyOf(node, node != nil)
+}
+
+// Max returns the largest element, second return parameter is false if the set is empty.
+func (set *OrderedSet[T]) Max() (T, bool) {
+	node := set.tree.Right()
+	return keyOf(node, node != nil)
+}
+
+// PopMin removes and returns the smallest element, second return parameter is false if the set is empty.
+func (set *OrderedSet[T]) PopMin() (T, bool) {
+	item, found := set.Min()
+	if found {
+		set.tree.Remove(item)
+	}
+	return item, found
+}
+
+// PopMax removes and returns the largest element, second return parameter is false if the set is empty.
+func (set *OrderedSet[T]) PopMax() (T, bool) {
+	item, found := set.Max()
+	if found {
+		set.tree.Remove(item)
+	}
+	return item, found
+}
+
+func keyOf[T comparable](node *RBNode[T, struct{}], found bool) (key T, ok bool) {
+	if !found {
+		return key, false
+	}
+	return node.Key, true
+}
+
+// Ascend returns a sequence of elements in [from, to) in ascending order.
+// Can be used with range-over-func (go 1.23+): for x := range set.Ascend(l, r) { ... }
+// or called directly: set.Ascend(l, r)(func(x T) bool { ...; return true }).
+// The set must not be modified during the iteration.
+func (set *OrderedSet[T]) Ascend(from, to T) func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		node, found := set.tree.Ceiling(from)
+		if !found {
+			return
+		}
+		for it := set.tree.IteratorAt(node); set.tree.Comparator(it.Key(), to) < 0; {
+			if !yield(it.Key()) || !it.Next() {
+				return
+			}
+		}
+	}
+}
+
+// Descend returns a sequence of elements in (to, from] in descending order, see Ascend.
+func (set *OrderedSet[T]) Descend(from, to T) func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		node, found := set.tree.Floor(from)
+		if !found {
+			return
+		}
+		for it := set.tree.IteratorAt(node); set.tree.Comparator(it.Key(), to) > 0; {
+			if !yield(it.Key()) || !it.Prev() {
+				return
+			}
+		}
+	}
+}
+
 // String returns a string representation of container
 func (set *OrderedSet[T]) String() string {
 	str := "TreeSet\n"
@@ -174,6 +299,34 @@ func (set *OrderedSet[T]) Iterator() OrderedSetIterator[T] {
 	return OrderedSetIterator[T]{index: -1, iterator: set.tree.Iterator(), tree: set.tree}
 }
 
+// LowerBoundIterator returns an iterator positioned at the smallest element greater than or equal to the item,
+// Value() and Index() are available right away. If there is no such element, second return parameter is false
+// and the iterator is one-past-the-end, so Prev() moves it to the last element.
+func (set *OrderedSet[T]) LowerBoundIterator(item T) (OrderedSetIterator[T], bool) {
+	node, found := set.tree.Ceiling(item)
+	return set.iteratorAt(node, found), found
+}
+
+// UpperBoundIterator returns an iterator positioned at the smallest element strictly greater than the item,
+// see LowerBoundIterator.
+func (set *OrderedSet[T]) UpperBoundIterator(item T) (OrderedSetIterator[T], bool) {
+	node, found := set.tree.Higher(item)
+	return set.iteratorAt(node, found), found
+}
+
+func (set *OrderedSet[T]) iteratorAt(node *RBNode[T, struct{}], found bool) OrderedSetIterator[T] {
+	if !found {
+		it := set.Iterator()
+		it.End()
+		return it
+	}
+	return OrderedSetIterator[T]{
+		index:    set.tree.Rank(node.Key),
+		iterator: set.tree.IteratorAt(node),
+		tree:     set.tree,
+	}
+}
+
 // Next moves the iterator to the next element and returns true if there was a next element in the container.
 // If Next() returns true, then next element's index and value can be retrieved by Index() and Value().
 // If Next() was called for the first time, then it will point the iterator to the first element if it exists.
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+)
+
+// setModel is a sorted slice of distinct values, the reference for OrderedSet
+type setModel []int
+
+func (m setModel) lowerBound(x int) (int, bool) {
+	i := sort.SearchInts(m, x)
+	return m.at(i)
+}
+
+func (m setModel) upperBound(x int) (int, bool) {
+	i := sort.SearchInts(m, x+1)
+	return m.at(i)
+}
+
+func (m setModel) floor(x int) (int, bool) {
+	i := sort.SearchInts(m, x+1) - 1
+	return m.at(i)
+}
+
+func (m setModel) lower(x int) (int, bool) {
+	i := sort.SearchInts(m, x) - 1
+	return m.at(i)
+}
+
+func (m setModel) at(i int) (int, bool) {
+	if i < 0 || i >= len(m) {
+		return 0, false
+	}
+	return m[i], true
+}
+
+// collect calls the sequence directly, without range-over-func
+func collect(seq func(yield func(int) bool)) []int {
+	res := []int{}
+	seq(func(x int) bool {
+		res = append(res, x)
+		return true
+	})
+	return res
+}
+
+func checkBound(t *testing.T, name string, x, got int, gotOk bool, want int, wantOk bool) {
+	t.Helper()
+	if gotOk != wantOk || (wantOk && got != want) {
+		t.Fatalf("%s(%d) = %d, %v, want %d, %v", name, x, got, gotOk, want, wantOk)
+	}
+}
+
+func TestOrderedSetBounds(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 200; iter++ {
+		set := NewOrderedSet[int]()
+		model := setModel{}
+		for op := 0; op < 100; op++ {
+			x := r.Intn(40)
+			if r.Intn(3) == 0 {
+				set.Remove(x)
+				if i, found := slices.BinarySearch(model, x); found {
+					model = slices.Delete(model, i, i+1)
+				}
+			} else {
+				set.Add(x)
+				if i, found := slices.BinarySearch(model, x); !found {
+					model = slices.Insert(model, i, x)
+				}
+			}
+
+			// запросы и за пределами значений множества
+			for q := -1; q <= 41; q++ {
+				got, ok := set.LowerBound(q)
+				want, wantOk := model.lowerBound(q)
+				checkBound(t, "LowerBound", q, got, ok, want, wantOk)
+				got, ok = set.Ceiling(q)
+				checkBound(t, "Ceiling", q, got, ok, want, wantOk)
+
+				got, ok = set.UpperBound(q)
+				want, wantOk = model.upperBound(q)
+				checkBound(t, "UpperBound", q, got, ok, want, wantOk)
+				got, ok = set.Higher(q)
+				checkBound(t, "Higher", q, got, ok, want, wantOk)
+				node, found := set.tree.Higher(q)
+				if found != wantOk || (found && node.Key != want) {
+					t.Fatalf("RBTree.Higher(%d) is wrong, want %d, %v", q, want, wantOk)
+				}
+
+				got, ok = set.Floor(q)
+				want, wantOk = model.floor(q)
+				checkBound(t, "Floor", q, got, ok, want, wantOk)
+
+				got, ok = set.Lower(q)
+				want, wantOk = model.lower(q)
+				checkBound(t, "Lower", q, got, ok, want, wantOk)
+				node, found = set.tree.Lower(q)
+				if found != wantOk || (found && node.Key != want) {
+					t.Fatalf("RBTree.Lower(%d) is wrong, want %d, %v", q, want, wantOk)
+				}
+			}
+		}
+	}
+}
+
+func TestOrderedSetBoundIterators(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 200; iter++ {
+		model := setModel{}
+		set := NewOrderedSet[int]()
+		for n := r.Intn(20); len(model) < n; {
+			x := r.Intn(40)
+			if i, found := slices.BinarySearch(model, x); !found {
+				model = slices.Insert(model, i, x)
+				set.Add(x)
+			}
+		}
+
+		for q := -1; q <= 41; q++ {
+			for _, upper := range []bool{false, true} {
+				name, bound, i := "LowerBoundIterator", set.LowerBoundIterator, sort.SearchInts(model, q)
+				if upper {
+					name, bound, i = "UpperBoundIterator", set.UpperBoundIterator, sort.SearchInts(model, q+1)
+				}
+				it, found := bound(q)
+
+				if found != (i < len(model)) {
+					t.Fatalf("%s(%d) found = %v, want %v", name, q, found, i < len(model))
+				}
+				if it.Index() != i {
+					t.Fatalf("%s(%d).Index() = %d, want %d", name, q, it.Index(), i)
+				}
+				if found && it.Value() != model[i] {
+					t.Fatalf("%s(%d).Value() = %d, want %d", name, q, it.Value(), model[i])
+				}
+
+				// копия итератора разделяет позицию с оригиналом, поэтому для каждого обхода берется новый.
+				// Из end-итератора Prev() переходит на последний элемент
+				prev, _ := bound(q)
+				if prev.Prev() != (i > 0) {
+					t.Fatalf("%s(%d).Prev() = %v, want %v", name, q, !(i > 0), i > 0)
+				}
+				if i > 0 && (prev.Value() != model[i-1] || prev.Index() != i-1) {
+					t.Fatalf("%s(%d).Prev() moved to %d (index %d), want %d (index %d)",
+						name, q, prev.Value(), prev.Index(), model[i-1], i-1)
+				}
+
+				if found {
+					next, _ := bound(q)
+					if next.Next() != (i+1 < len(model)) {
+						t.Fatalf("%s(%d).Next() = %v, want %v", name, q, !(i+1 < len(model)), i+1 < len(model))
+					}
+					if i+1 < len(model) && next.Value() != model[i+1] {
+						t.Fatalf("%s(%d).Next() moved to %d, want %d", name, q, next.Value(), model[i+1])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestOrderedSetEndIteratorPrev(t *testing.T) {
+	set := NewOrderedSet(1, 3, 5)
+	it, found := set.LowerBoundIterator(6)
+	if found {
+		t.Fatalf("LowerBoundIterator(6) found = true, want false")
+	}
+	for _, want := range []int{5, 3, 1} {
+		if !it.Prev() || it.Value() != want {
+			t.Fatalf("Prev() from the end: got %d, want %d", it.Value(), want)
+		}
+	}
+	if it.Prev() {
+		t.Fatalf("Prev() before the first element returned true")
+	}
+
+	empty := NewOrderedSet[int]()
+	it, found = empty.UpperBoundIterator(0)
+	if found || it.Prev() || it.Next() {
+		t.Fatalf("iterator of an empty set must not move")
+	}
+}
+
+func TestOrderedSetPopMinMax(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 200; iter++ {
+		set := NewOrderedSet[int]()
+		model := setModel{}
+		for op := 0; op < 60; op++ {
+			switch r.Intn(4) {
+			case 0:
+				got, ok := set.PopMin()
+				want, wantOk := model.at(0)
+				checkBound(t, "PopMin", 0, got, ok, want, wantOk)
+				if wantOk {
+					model = model[1:]
+				}
+			case 1:
+				got, ok := set.PopMax()
+				want, wantOk := model.at(len(model) - 1)
+				checkBound(t, "PopMax", 0, got, ok, want, wantOk)
+				if wantOk {
+					model = model[:len(model)-1]
+				}
+			default:
+				x := r.Intn(30)
+				set.Add(x)
+				if i, found := slices.BinarySearch(model, x); !found {
+					model = slices.Insert(model, i, x)
+				}
+			}
+			if !slices.Equal(set.Values(), model) {
+				t.Fatalf("Values() = %v, want %v", set.Values(), model)
+			}
+		}
+	}
+}
+
+func TestOrderedSetAscendDescend(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 200; iter++ {
+		model := setModel{}
+		set := NewOrderedSet[int]()
+		for n := r.Intn(20); len(model) < n; {
+			x := r.Intn(40)
+			if i, found := slices.BinarySearch(model, x); !found {
+				model = slices.Insert(model, i, x)
+				set.Add(x)
+			}
+		}
+
+		// в том числе пустые диапазоны from >= to
+		for from := -1; from <= 41; from++ {
+			for to := -1; to <= 41; to++ {
+				want := []int{}
+				for _, x := range model {
+					if from <= x && x < to {
+						want = append(want, x)
+					}
+				}
+				if got := collect(set.Ascend(from, to)); !slices.Equal(got, want) {
+					t.Fatalf("Ascend(%d, %d) = %v, want %v", from, to, got, want)
+				}
+
+				want = []int{}
+				for k := len(model) - 1; k >= 0; k-- {
+					if to < model[k] && model[k] <= from {
+						want = append(want, model[k])
+					}
+				}
+				if got := collect(set.Descend(from, to)); !slices.Equal(got, want) {
+					t.Fatalf("Descend(%d, %d) = %v, want %v", from, to, got, want)
+				}
+			}
+		}
+
+		// остановка по yield == false
+		if len(model) > 1 {
+			got := []int{}
+			set.Ascend(model[0], model[len(model)-1]+1)(func(x int) bool {
+				got = append(got, x)
+				return len(got) < 2
+			})
+			if !slices.Equal(got, model[:2]) {
+				t.Fatalf("Ascend stopped after %v, want %v", got, model[:2])
+			}
+		}
+	}
+}